@@ -0,0 +1,85 @@
+//go:build !generate
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/conductorone/baton-outreach/pkg/connector"
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/oauth2"
+)
+
+// loginTimeout is how long the login waits for the user to authorize the application.
+const loginTimeout = 5 * time.Minute
+
+// newLoginCommand returns the command getting a refresh token with the OAuth authorization code flow.
+// The tokens are saved to the token store when one is configured, otherwise the refresh token is printed.
+func newLoginCommand(ctx context.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "login",
+		Short: "Authorize the connector on Outreach and get a refresh token",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			flags := cmd.Flags()
+			clientID, _ := flags.GetString("outreach-client-id")
+			clientSecret, _ := flags.GetString("outreach-client-secret")
+			tokenStorePath, _ := flags.GetString("token-store-path")
+			tokenStoreKey, _ := flags.GetString("token-store-key")
+			redirectPort, _ := flags.GetInt("redirect-port")
+			tokenURL, _ := flags.GetString("token-url")
+			readOnly, _ := flags.GetBool("read-only")
+
+			httpClient, err := client.NewHTTPClient(ctx, transportConfig(flags))
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.WithValue(ctx, oauth2.HTTPClient, httpClient), loginTimeout)
+			defer cancel()
+
+			token, err := client.Login(ctx, client.LoginConfig{
+				ClientID:     clientID,
+				ClientSecret: clientSecret,
+				Scopes:       connector.RequiredScopes(readOnly),
+				RedirectPort: redirectPort,
+				TokenURL:     tokenURL,
+				OpenURL: func(authorizationURL string) error {
+					_, err := fmt.Fprintf(cmd.ErrOrStderr(), "Open the following URL in a browser to authorize the connector:\n\n%s\n\n", authorizationURL)
+					return err
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			if tokenStorePath == "" {
+				_, err = fmt.Fprintf(cmd.OutOrStdout(), "Refresh token: %s\n", token.RefreshToken)
+				return err
+			}
+
+			err = client.NewFileTokenStore(tokenStorePath, tokenStoreKey).Save(token)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(cmd.ErrOrStderr(), "The tokens were saved to %s\n", tokenStorePath)
+			return err
+		},
+	}
+}
+
+func transportConfig(flags *pflag.FlagSet) client.TransportConfig {
+	var config client.TransportConfig
+	config.ProxyURL, _ = flags.GetString("proxy-url")
+	config.ProxyUsername, _ = flags.GetString("proxy-username")
+	config.ProxyPassword, _ = flags.GetString("proxy-password")
+	config.CABundlePath, _ = flags.GetString("ca-bundle-path")
+	config.ClientCertPath, _ = flags.GetString("client-cert-path")
+	config.ClientKeyPath, _ = flags.GetString("client-key-path")
+
+	return config
+}
@@ -6,15 +6,19 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	cfg "github.com/conductorone/baton-outreach/pkg/config"
 	"github.com/conductorone/baton-outreach/pkg/connector"
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	"github.com/conductorone/baton-sdk/pkg/cli"
 	"github.com/conductorone/baton-sdk/pkg/config"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/field"
 	"github.com/conductorone/baton-sdk/pkg/types"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"go.uber.org/zap"
+	"golang.org/x/oauth2"
 )
 
 var version = "dev"
@@ -22,7 +26,7 @@ var version = "dev"
 func main() {
 	ctx := context.Background()
 
-	_, cmd, err := config.DefineConfiguration(
+	v, cmd, err := config.DefineConfiguration(
 		ctx,
 		"baton-outreach",
 		getConnector,
@@ -33,6 +37,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	_, err = cli.AddCommand(cmd, v, &cfg.LoginConfig, newLoginCommand(ctx))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
 	cmd.Version = version
 
 	err = cmd.Execute()
@@ -49,9 +59,43 @@ func getConnector(ctx context.Context, config *cfg.Outreach) (types.ConnectorSer
 		return nil, err
 	}
 
+	httpClient, err := client.NewHTTPClient(ctx, client.TransportConfig{
+		ProxyURL:       config.ProxyUrl,
+		ProxyUsername:  config.ProxyUsername,
+		ProxyPassword:  config.ProxyPassword,
+		CABundlePath:   config.CaBundlePath,
+		ClientCertPath: config.ClientCertPath,
+		ClientKeyPath:  config.ClientKeyPath,
+	})
+	if err != nil {
+		l.Error("error creating the http client", zap.Error(err))
+		return nil, err
+	}
+
+	clientOptions := []client.ConfigOption{
+		client.WithHTTPClient(httpClient),
+		client.WithBaseURL(config.BaseUrl),
+		client.WithTokenURL(config.TokenUrl),
+		client.WithRateLimitReserve(config.RateLimitReserve),
+		client.WithPageSize(config.PageSize),
+		client.WithBatching(client.BatchOptions{Window: time.Duration(config.BatchWindowMs) * time.Millisecond}),
+	}
+	if config.TokenStorePath != "" {
+		clientOptions = append(clientOptions, client.WithTokenStore(client.NewFileTokenStore(config.TokenStorePath, config.TokenStoreKey)))
+	}
+
+	connectorOptions := []connector.Option{
+		connector.WithClientOptions(clientOptions...),
+		connector.WithFullSyncInterval(time.Duration(config.FullSyncIntervalHours) * time.Hour),
+		connector.WithTeamFetchWorkers(config.TeamFetchWorkers),
+		connector.WithFallbackProfile(config.FallbackProfile),
+		connector.WithRestorePreviousProfile(config.RestorePreviousProfile),
+		connector.WithReadOnly(config.ReadOnly),
+	}
+
 	accessToken := config.AccessToken
 	if accessToken != "" {
-		cbWithAccessToken, err := connector.NewWithAccessToken(ctx, accessToken)
+		cbWithAccessToken, err := connector.NewWithAccessToken(ctx, accessToken, connectorOptions...)
 		if err != nil {
 			l.Error("error creating connector with access token", zap.Error(err))
 			return nil, err
@@ -64,8 +108,8 @@ func getConnector(ctx context.Context, config *cfg.Outreach) (types.ConnectorSer
 	outreachClientID := config.OutreachClientId
 	outreachClientSecret := config.OutreachClientSecret
 
-	if outreachClientID != "" && outreachClientSecret != "" && refreshToken != "" {
-		cbWithRefreshToken, err := connector.NewWithRefreshToken(ctx, outreachClientID, outreachClientSecret, refreshToken)
+	if outreachClientID != "" && outreachClientSecret != "" && (refreshToken != "" || config.TokenStorePath != "") {
+		cbWithRefreshToken, err := connector.NewWithRefreshToken(ctx, outreachClientID, outreachClientSecret, refreshToken, connectorOptions...)
 		if err != nil {
 			l.Error("error creating connector with refresh token", zap.Error(err))
 			return nil, err
@@ -74,6 +118,35 @@ func getConnector(ctx context.Context, config *cfg.Outreach) (types.ConnectorSer
 		cb = cbWithRefreshToken
 	}
 
+	if config.AppId != "" {
+		privateKey, err := appPrivateKey(config)
+		if err != nil {
+			l.Error("error reading the app private key", zap.Error(err))
+			return nil, err
+		}
+
+		// The installation tokens are requested with the same HTTP client as the API requests.
+		oauthCtx := context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+		tokenSource, err := client.NewAppTokenSource(oauthCtx, client.AppConfig{
+			AppID:          config.AppId,
+			InstallationID: config.AppInstallationId,
+			PrivateKey:     privateKey,
+			BaseURL:        config.BaseUrl,
+		})
+		if err != nil {
+			l.Error("error creating the app token source", zap.Error(err))
+			return nil, err
+		}
+
+		cbWithApp, err := connector.NewWithTokenSource(ctx, tokenSource, connectorOptions...)
+		if err != nil {
+			l.Error("error creating connector with app authentication", zap.Error(err))
+			return nil, err
+		}
+
+		cb = cbWithApp
+	}
+
 	if cb == nil {
 		return nil, fmt.Errorf("connector initialization failed")
 	}
@@ -85,3 +158,12 @@ func getConnector(ctx context.Context, config *cfg.Outreach) (types.ConnectorSer
 	}
 	return conn, nil
 }
+
+// appPrivateKey returns the private key of the S2S app, read from its file when a path is given.
+func appPrivateKey(config *cfg.Outreach) ([]byte, error) {
+	if config.AppPrivateKeyPath != "" {
+		return os.ReadFile(config.AppPrivateKeyPath)
+	}
+
+	return []byte(config.AppPrivateKey), nil
+}
@@ -8,6 +8,28 @@ type Outreach struct {
 	RefreshToken string `mapstructure:"refresh-token"`
 	OutreachClientSecret string `mapstructure:"outreach-client-secret"`
 	OutreachClientId string `mapstructure:"outreach-client-id"`
+	TokenStorePath string `mapstructure:"token-store-path"`
+	TokenStoreKey string `mapstructure:"token-store-key"`
+	AppId string `mapstructure:"app-id"`
+	AppInstallationId string `mapstructure:"app-installation-id"`
+	AppPrivateKeyPath string `mapstructure:"app-private-key-path"`
+	AppPrivateKey string `mapstructure:"app-private-key"`
+	BaseUrl string `mapstructure:"base-url"`
+	TokenUrl string `mapstructure:"token-url"`
+	ProxyUrl string `mapstructure:"proxy-url"`
+	ProxyUsername string `mapstructure:"proxy-username"`
+	ProxyPassword string `mapstructure:"proxy-password"`
+	CaBundlePath string `mapstructure:"ca-bundle-path"`
+	ClientCertPath string `mapstructure:"client-cert-path"`
+	ClientKeyPath string `mapstructure:"client-key-path"`
+	RateLimitReserve int `mapstructure:"rate-limit-reserve"`
+	FullSyncIntervalHours int `mapstructure:"full-sync-interval-hours"`
+	TeamFetchWorkers int `mapstructure:"team-fetch-workers"`
+	BatchWindowMs int `mapstructure:"batch-window-ms"`
+	FallbackProfile string `mapstructure:"fallback-profile"`
+	RestorePreviousProfile bool `mapstructure:"restore-previous-profile"`
+	PageSize int `mapstructure:"page-size"`
+	ReadOnly bool `mapstructure:"read-only"`
 }
 
 func (c* Outreach) findFieldByTag(tagValue string) (any, bool) {
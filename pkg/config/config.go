@@ -12,6 +12,9 @@ var (
 	// that will refresh automatically the access token when expired.
 	// Those flags should be used when executing the connector on the CLI in service mode.
 
+	// 'app-id', 'app-installation-id' and one of 'app-private-key-path' or 'app-private-key' authenticate as an installation
+	// of an Outreach server-to-server app, so the connector doesn't depend on the refresh token of any user.
+
 	accessTokenField = field.StringField("access-token",
 		field.WithDisplayName("Access Token"),
 		field.WithDescription("Generated access token to communicate with Outreach API. Only for CLI one-shot executions."),
@@ -38,21 +41,252 @@ var (
 		field.WithRequired(false),
 	)
 
+	tokenStorePathField = field.StringField("token-store-path",
+		field.WithDisplayName("Token store path"),
+		field.WithDescription("File where the refresh tokens rotated by Outreach are saved. When it holds a token, it is used instead of the 'refresh-token' flag. Only for CLI executions."),
+		field.WithRequired(false),
+	)
+
+	tokenStoreKeyField = field.StringField("token-store-key",
+		field.WithDisplayName("Token store encryption key"),
+		field.WithDescription("Passphrase used to encrypt the token store. The token is saved unencrypted when empty."),
+		field.WithRequired(false),
+		field.WithIsSecret(true),
+	)
+
+	appIDField = field.StringField("app-id",
+		field.WithDisplayName("Outreach S2S app ID"),
+		field.WithDescription("ID of the Outreach server-to-server app, to authenticate as an app installation instead of a user."),
+		field.WithRequired(false),
+	)
+
+	appInstallationIDField = field.StringField("app-installation-id",
+		field.WithDisplayName("Outreach S2S app installation ID"),
+		field.WithDescription("ID of the installation of the Outreach server-to-server app."),
+		field.WithRequired(false),
+	)
+
+	appPrivateKeyPathField = field.StringField("app-private-key-path",
+		field.WithDisplayName("Outreach S2S app private key path"),
+		field.WithDescription("Path to the PEM encoded RSA private key of the Outreach server-to-server app."),
+		field.WithRequired(false),
+	)
+
+	appPrivateKeyField = field.StringField("app-private-key",
+		field.WithDisplayName("Outreach S2S app private key"),
+		field.WithDescription("PEM encoded RSA private key of the Outreach server-to-server app."),
+		field.WithRequired(false),
+		field.WithIsSecret(true),
+	)
+
+	baseURLField = field.StringField("base-url",
+		field.WithDisplayName("Outreach API base URL"),
+		field.WithDescription("Base URL of the Outreach API, e.g. for a regional host, an egress proxy or a local mock. Must use HTTPS unless it points to the loopback interface."),
+		field.WithRequired(false),
+		field.WithDefaultValue("https://api.outreach.io/api/v2"),
+	)
+
+	tokenURLField = field.StringField("token-url",
+		field.WithDisplayName("Outreach OAuth token URL"),
+		field.WithDescription("URL where the Outreach OAuth tokens are issued. Must use HTTPS unless it points to the loopback interface."),
+		field.WithRequired(false),
+		field.WithDefaultValue("https://api.outreach.io/oauth/token"),
+	)
+
+	proxyURLField = field.StringField("proxy-url",
+		field.WithDisplayName("Proxy URL"),
+		field.WithDescription("URL of the HTTP proxy used to reach Outreach, for the API requests and the token requests."),
+		field.WithRequired(false),
+	)
+
+	proxyUsernameField = field.StringField("proxy-username",
+		field.WithDisplayName("Proxy username"),
+		field.WithDescription("Username to authenticate with the proxy."),
+		field.WithRequired(false),
+	)
+
+	proxyPasswordField = field.StringField("proxy-password",
+		field.WithDisplayName("Proxy password"),
+		field.WithDescription("Password to authenticate with the proxy."),
+		field.WithRequired(false),
+		field.WithIsSecret(true),
+	)
+
+	caBundlePathField = field.StringField("ca-bundle-path",
+		field.WithDisplayName("CA bundle path"),
+		field.WithDescription("Path to a PEM file of CA certificates trusted on top of the system ones, e.g. the CA of a TLS inspecting proxy."),
+		field.WithRequired(false),
+	)
+
+	clientCertPathField = field.StringField("client-cert-path",
+		field.WithDisplayName("Client certificate path"),
+		field.WithDescription("Path to the PEM encoded client certificate presented for mutual TLS."),
+		field.WithRequired(false),
+	)
+
+	clientKeyPathField = field.StringField("client-key-path",
+		field.WithDisplayName("Client key path"),
+		field.WithDescription("Path to the PEM encoded private key of the client certificate."),
+		field.WithRequired(false),
+	)
+
+	// TransportFields configure how Outreach is reached, shared with the 'login' command.
+	TransportFields = []field.SchemaField{
+		proxyURLField,
+		proxyUsernameField,
+		proxyPasswordField,
+		caBundlePathField,
+		clientCertPathField,
+		clientKeyPathField,
+	}
+
+	transportRelationships = []field.SchemaFieldRelationship{
+		field.FieldsDependentOn(
+			[]field.SchemaField{proxyUsernameField, proxyPasswordField},
+			[]field.SchemaField{proxyURLField},
+		),
+		field.FieldsRequiredTogether(clientCertPathField, clientKeyPathField),
+	}
+
+	rateLimitReserveField = field.IntField("rate-limit-reserve",
+		field.WithDisplayName("Rate limit reserve"),
+		field.WithDescription("Percentage of the Outreach hourly request budget that syncs leave for provisioning requests."),
+		field.WithRequired(false),
+		field.WithDefaultValue(10),
+	)
+
+	fullSyncIntervalField = field.IntField("full-sync-interval-hours",
+		field.WithDisplayName("Full sync interval (hours)"),
+		field.WithDescription("Hours between full lists of the users, teams and profiles. In between, only the ones updated since the previous sync are requested while the connector keeps running. 0 always lists them in full."),
+		field.WithRequired(false),
+		field.WithDefaultValue(0),
+	)
+
+	teamFetchWorkersField = field.IntField("team-fetch-workers",
+		field.WithDisplayName("Team fetch workers"),
+		field.WithDescription("Number of teams requested concurrently while syncing the team memberships. The requests still follow the Outreach rate limit. 0 requests them one at a time."),
+		field.WithRequired(false),
+		field.WithDefaultValue(4),
+	)
+
+	batchWindowField = field.IntField("batch-window-ms",
+		field.WithDisplayName("Batch window (milliseconds)"),
+		field.WithDescription("Milliseconds the team membership and profile changes are collected before being submitted together through the Outreach batch API. 0 updates each resource on its own."),
+		field.WithRequired(false),
+		field.WithDefaultValue(0),
+	)
+
+	fallbackProfileField = field.StringField("fallback-profile",
+		field.WithDisplayName("Fallback profile"),
+		field.WithDescription("Name of the profile users are moved to when their profile is revoked. Defaults to the 'Default' profile of the organization."),
+		field.WithRequired(false),
+	)
+
+	restorePreviousProfileField = field.BoolField("restore-previous-profile",
+		field.WithDisplayName("Restore previous profile"),
+		field.WithDescription("On revoke, move users back to the profile they had before the grant instead of the fallback profile. Only the grants made since the connector started are known."),
+		field.WithRequired(false),
+		field.WithDefaultValue(false),
+	)
+
+	pageSizeField = field.IntField("page-size",
+		field.WithDisplayName("Page size"),
+		field.WithDescription("Number of resources requested per page, up to 1000."),
+		field.WithRequired(false),
+		field.WithDefaultValue(100),
+	)
+
+	readOnlyField = field.BoolField("read-only",
+		field.WithDisplayName("Read only"),
+		field.WithDescription("Only sync from Outreach, without registering any provisioning capability. Capabilities the token can't perform are never registered."),
+		field.WithRequired(false),
+		field.WithDefaultValue(false),
+	)
+
 	ConfigurationFields = []field.SchemaField{
 		accessTokenField,
 
 		refreshToken,
 		outreachClientSecretField,
 		outreachClientIDField,
+		tokenStorePathField,
+		tokenStoreKeyField,
+
+		appIDField,
+		appInstallationIDField,
+		appPrivateKeyPathField,
+		appPrivateKeyField,
+
+		baseURLField,
+		tokenURLField,
+
+		proxyURLField,
+		proxyUsernameField,
+		proxyPasswordField,
+		caBundlePathField,
+		clientCertPathField,
+		clientKeyPathField,
+
+		rateLimitReserveField,
+		fullSyncIntervalField,
+		teamFetchWorkersField,
+		batchWindowField,
+		fallbackProfileField,
+		restorePreviousProfileField,
+		pageSizeField,
+		readOnlyField,
 	}
 
 	// FieldRelationships defines relationships between the ConfigurationFields that can be automatically validated.
 	// For example, a username and password can be required together, or an access token can be
 	// marked as mutually exclusive from the username password pair.
-	FieldRelationships = []field.SchemaFieldRelationship{
-		field.FieldsAtLeastOneUsed(accessTokenField, refreshToken),
-		field.FieldsMutuallyExclusive(accessTokenField, refreshToken),
-		field.FieldsRequiredTogether(outreachClientSecretField, outreachClientIDField, refreshToken)}
+	// The refresh token can be omitted when the token store already holds one.
+	// The app authenticates on its own, so none of the options of the refresh token flow can be used with it.
+	FieldRelationships = append([]field.SchemaFieldRelationship{
+		field.FieldsAtLeastOneUsed(accessTokenField, refreshToken, tokenStorePathField, appIDField),
+		field.FieldsMutuallyExclusive(accessTokenField, refreshToken, appIDField),
+		field.FieldsMutuallyExclusive(appIDField, tokenStorePathField),
+		field.FieldsMutuallyExclusive(appIDField, outreachClientIDField),
+		field.FieldsRequiredTogether(outreachClientSecretField, outreachClientIDField),
+		field.FieldsDependentOn(
+			[]field.SchemaField{refreshToken, tokenStorePathField},
+			[]field.SchemaField{outreachClientSecretField, outreachClientIDField},
+		),
+		field.FieldsRequiredTogether(appIDField, appInstallationIDField),
+		field.FieldsMutuallyExclusive(appPrivateKeyPathField, appPrivateKeyField),
+		field.FieldsDependentOn(
+			[]field.SchemaField{appPrivateKeyPathField, appPrivateKeyField},
+			[]field.SchemaField{appIDField, appInstallationIDField},
+		),
+	}, transportRelationships...)
+)
+
+var (
+	redirectPortField = field.IntField("redirect-port",
+		field.WithDisplayName("Redirect port"),
+		field.WithDescription("Loopback port receiving the authorization, as registered in the redirect URI of the Outreach application (http://127.0.0.1:<port>/callback)."),
+		field.WithRequired(false),
+		field.WithDefaultValue(8085),
+	)
+
+	// LoginFields are the options of the 'login' command, which gets a refresh token for the connector.
+	LoginFields = append([]field.SchemaField{
+		outreachClientSecretField,
+		outreachClientIDField,
+		tokenStorePathField,
+		tokenStoreKeyField,
+		redirectPortField,
+		tokenURLField,
+		readOnlyField,
+	}, TransportFields...)
+
+	LoginConfig = field.NewConfiguration(
+		LoginFields,
+		field.WithConstraints(append(
+			[]field.SchemaFieldRelationship{field.FieldsRequiredTogether(outreachClientSecretField, outreachClientIDField)},
+			transportRelationships...,
+		)...),
+	)
 )
 
 //go:generate go run -tags=generate ./gen
@@ -2,9 +2,109 @@ package config
 
 import (
 	"testing"
+
+	"github.com/conductorone/baton-sdk/pkg/field"
 )
 
 func TestValidateConfig(t *testing.T) {
 	// There is no point on validating config since it's an OAuth connector.
 	// Providing and access token is optional for a CLI execution and doing tests.
 }
+
+// testConfig holds the values of the flags set in a test, the others being empty.
+type testConfig map[string]any
+
+func (c testConfig) GetString(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+
+func (c testConfig) GetBool(key string) bool {
+	value, _ := c[key].(bool)
+	return value
+}
+
+func (c testConfig) GetInt(key string) int {
+	value, _ := c[key].(int)
+	return value
+}
+
+func (c testConfig) GetStringSlice(key string) []string {
+	value, _ := c[key].([]string)
+	return value
+}
+
+func (c testConfig) GetStringMap(key string) map[string]any {
+	value, _ := c[key].(map[string]any)
+	return value
+}
+
+func TestConfigRelationships(t *testing.T) {
+	app := testConfig{
+		"app-id":               "app",
+		"app-installation-id":  "installation",
+		"app-private-key-path": "key.pem",
+	}
+	with := func(config testConfig, key string, value any) testConfig {
+		merged := testConfig{key: value}
+		for k, v := range config {
+			merged[k] = v
+		}
+		return merged
+	}
+
+	tests := []struct {
+		name   string
+		config testConfig
+		// wantErr is the error the configuration is rejected with, if any.
+		wantErr string
+	}{
+		{
+			name: "refresh token",
+			config: testConfig{
+				"refresh-token":          "token",
+				"outreach-client-id":     "client",
+				"outreach-client-secret": "secret",
+			},
+		},
+		{
+			name: "token store",
+			config: testConfig{
+				"token-store-path":       "token.json",
+				"outreach-client-id":     "client",
+				"outreach-client-secret": "secret",
+			},
+		},
+		{
+			name:   "app",
+			config: app,
+		},
+		{
+			name:    "app with a refresh token",
+			config:  with(app, "refresh-token", "token"),
+			wantErr: "fields marked as mutually exclusive were set: ('refresh-token' and 'app-id')",
+		},
+		{
+			name:    "app with a token store",
+			config:  with(app, "token-store-path", "token.json"),
+			wantErr: "fields marked as mutually exclusive were set: ('app-id' and 'token-store-path')",
+		},
+		{
+			name:    "app with an OAuth client",
+			config:  with(with(app, "outreach-client-id", "client"), "outreach-client-secret", "secret"),
+			wantErr: "fields marked as mutually exclusive were set: ('app-id' and 'outreach-client-id')",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := field.Validate(Config, tt.config)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("expected the configuration to be valid, got %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("expected the error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package connector
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// provisioningScopes maps each resource type to the Outreach resource its provisioning writes to.
+// Profiles and roles are provisioned by updating the profile and the role of the users.
+var provisioningScopes = map[string]string{
+	userResourceType.Id:     "users",
+	teamResourceType.Id:     "teams",
+	profileResourceType.Id:  "users",
+	roleResourceType.Id:     "users",
+	sequenceResourceType.Id: "sequences",
+}
+
+// optionalReadScopes maps the resource types that are only synced when the token can read them to the Outreach resource
+// they read. Tokens granted before those resource types were supported lack their scopes, and keep syncing the others.
+var optionalReadScopes = map[string]string{
+	roleResourceType.Id:     "roles",
+	mailboxResourceType.Id:  "mailboxes",
+	sequenceResourceType.Id: "sequences",
+}
+
+// tokenInfoLookupTimeout bounds the request finding the scopes of the token when registering the builders, so commands
+// like 'capabilities' run without a reachable API or a valid token don't hang.
+const tokenInfoLookupTimeout = 10 * time.Second
+
+// readOnlySyncer hides the provisioning methods of a builder, so its provisioning capabilities are not registered.
+type readOnlySyncer struct {
+	connectorbuilder.ResourceSyncer
+}
+
+// registrationTokenInfo returns the token information the builders are registered with, or nil when it can't be
+// found, like with an invalid token or an unreachable API, in which case every resource type is kept with its
+// provisioning capabilities.
+func (d *Connector) registrationTokenInfo(ctx context.Context) *client.TokenInfo {
+	lookupCtx, cancel := context.WithTimeout(ctx, tokenInfoLookupTimeout)
+	defer cancel()
+
+	tokenInfo, err := d.getTokenInfo(lookupCtx)
+	if err != nil {
+		ctxzap.Extract(ctx).Warn("unable to find the scopes of the token, keeping every resource type and their provisioning capabilities", zap.Error(err))
+		return nil
+	}
+
+	return tokenInfo
+}
+
+// provisionableSyncers returns the builders that can only sync when their provisioning isn't allowed, leaving out the
+// builders of the optional resource types the token can't read.
+func (d *Connector) provisionableSyncers(
+	ctx context.Context,
+	tokenInfo *client.TokenInfo,
+	builders ...connectorbuilder.ResourceSyncer,
+) []connectorbuilder.ResourceSyncer {
+	d.provisioningMu.Lock()
+	defer d.provisioningMu.Unlock()
+
+	syncers := make([]connectorbuilder.ResourceSyncer, 0, len(builders))
+	for _, builder := range builders {
+		resourceTypeID := builder.ResourceType(ctx).Id
+
+		if !canRead(resourceTypeID, tokenInfo) {
+			ctxzap.Extract(ctx).Warn(
+				"the token can't read the resource, the resource type is not synced",
+				zap.String("resource_type", resourceTypeID),
+				zap.String("missing_scope", optionalReadScopes[resourceTypeID]+"."+client.ScopeRead),
+			)
+			continue
+		}
+
+		provisionable := d.canProvision(ctx, resourceTypeID, tokenInfo)
+		d.provisioning[resourceTypeID] = provisionable
+		if provisionable {
+			syncers = append(syncers, builder)
+			continue
+		}
+
+		syncers = append(syncers, &readOnlySyncer{ResourceSyncer: builder})
+	}
+
+	return syncers
+}
+
+// canRead reports whether the resource type can be synced. Only the optional resource types are checked, the scopes
+// of the others are required by Validate. When the scopes are unknown, the resource type is kept, and Validate
+// reports the missing scopes later on.
+func canRead(resourceTypeID string, tokenInfo *client.TokenInfo) bool {
+	scope, ok := optionalReadScopes[resourceTypeID]
+	if !ok || tokenInfo == nil {
+		return true
+	}
+
+	return tokenInfo.HasScope(scope, client.ScopeRead)
+}
+
+// canProvision reports whether the resource type can be provisioned. Provisioning is disabled in read-only mode,
+// and when the token wasn't granted the write scope it needs. When the scopes are unknown, provisioning is kept,
+// and Validate reports the missing scopes later on.
+func (d *Connector) canProvision(ctx context.Context, resourceTypeID string, tokenInfo *client.TokenInfo) bool {
+	if d.readOnly {
+		return false
+	}
+
+	scope, ok := provisioningScopes[resourceTypeID]
+	if !ok || tokenInfo == nil {
+		return true
+	}
+
+	if !tokenInfo.HasScope(scope, client.ScopeWrite) {
+		ctxzap.Extract(ctx).Info(
+			"the token can't write to the resource, its provisioning capabilities are disabled",
+			zap.String("resource_type", resourceTypeID),
+			zap.String("missing_scope", scope+"."+client.ScopeWrite),
+		)
+		return false
+	}
+
+	return true
+}
+
+// requiredReadScopes returns the Outreach resources that must be readable for the resource types synced by the
+// connector: the ones read by every sync, and the ones of the optional resource types that were registered.
+func (d *Connector) requiredReadScopes() []string {
+	d.provisioningMu.Lock()
+	defer d.provisioningMu.Unlock()
+
+	scopes := slices.Clone(scopedResources)
+	for resourceTypeID := range d.provisioning {
+		if scope, ok := optionalReadScopes[resourceTypeID]; ok {
+			scopes = append(scopes, scope)
+		}
+	}
+	slices.Sort(scopes[len(scopedResources):])
+
+	return scopes
+}
+
+// requiredWriteScopes returns the Outreach resources that must be writable for the provisioning registered by the
+// connector, sorted so Validate reports them in a stable order.
+func (d *Connector) requiredWriteScopes() []string {
+	d.provisioningMu.Lock()
+	defer d.provisioningMu.Unlock()
+
+	var scopes []string
+	for resourceTypeID, provisionable := range d.provisioning {
+		scope, ok := provisioningScopes[resourceTypeID]
+		if ok && provisionable && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	slices.Sort(scopes)
+
+	return scopes
+}
+
+// RequiredScopes returns the OAuth scopes the connector needs: read access to every synced resource, including the
+// optional ones, and write access to the resources it provisions unless it is read-only.
+func RequiredScopes(readOnly bool) []string {
+	scopes := make([]string, 0, len(scopedResources)+len(optionalReadScopes))
+	for _, resource := range scopedResources {
+		scopes = append(scopes, resource+"."+client.ScopeRead)
+	}
+
+	var optionalScopes []string
+	for _, resource := range optionalReadScopes {
+		optionalScopes = append(optionalScopes, resource+"."+client.ScopeRead)
+	}
+	slices.Sort(optionalScopes)
+	scopes = append(scopes, optionalScopes...)
+
+	if readOnly {
+		return scopes
+	}
+
+	var writeScopes []string
+	for _, resource := range provisioningScopes {
+		scope := resource + "." + client.ScopeWrite
+		if !slices.Contains(writeScopes, scope) {
+			writeScopes = append(writeScopes, scope)
+		}
+	}
+	slices.Sort(writeScopes)
+
+	return append(scopes, writeScopes...)
+}
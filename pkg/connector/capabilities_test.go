@@ -0,0 +1,166 @@
+package connector
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	"github.com/conductorone/baton-outreach/pkg/outreachtest"
+	"google.golang.org/grpc/codes"
+)
+
+func TestConnectorOptionalResourceTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		scopes      []string
+		wantSyncers []string
+	}{
+		{
+			name:        "every scope",
+			scopes:      []string{"users.all", "teams.all", "profiles.read", "roles.read", "mailboxes.read", "sequences.all"},
+			wantSyncers: []string{"user", "team", "profile", "role", "mailbox", "sequence"},
+		},
+		{
+			name:        "token granted before the optional resource types",
+			scopes:      []string{"users.all", "teams.all", "profiles.read"},
+			wantSyncers: []string{"user", "team", "profile"},
+		},
+		{
+			name:        "some of the optional scopes",
+			scopes:      []string{"users.all", "teams.all", "profiles.read", "sequences.all"},
+			wantSyncers: []string{"user", "team", "profile", "sequence"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			_, d := newTestConnector(t, tt.scopes)
+
+			if got := syncerTypes(ctx, d.ResourceSyncers(ctx)); !slices.Equal(got, tt.wantSyncers) {
+				t.Errorf("expected the syncers %v, got %v", tt.wantSyncers, got)
+			}
+
+			_, err := d.Validate(ctx)
+			checkCode(t, err, codes.OK)
+		})
+	}
+}
+
+func TestConnectorSyncersWithoutTokenInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		// newConnector returns a connector whose token information can't be found.
+		newConnector func(t *testing.T) *Connector
+	}{
+		{
+			name: "invalid token",
+			newConnector: func(t *testing.T) *Connector {
+				server, err := outreachtest.NewServer(outreachtest.DefaultFixtures())
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(server.Close)
+
+				d, err := NewWithAccessToken(context.Background(), "invalid-token", WithClientOptions(client.WithBaseURL(server.URL)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return d
+			},
+		},
+		{
+			name: "unreachable API",
+			newConnector: func(t *testing.T) *Connector {
+				server, err := outreachtest.NewServer(outreachtest.DefaultFixtures())
+				if err != nil {
+					t.Fatal(err)
+				}
+				server.Close()
+
+				d, err := NewWithAccessToken(context.Background(), outreachtest.DefaultToken, WithClientOptions(client.WithBaseURL(server.URL)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return d
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			d := tt.newConnector(t)
+
+			want := []string{"user", "team", "profile", "role", "mailbox", "sequence"}
+			if got := syncerTypes(ctx, d.ResourceSyncers(ctx)); !slices.Equal(got, want) {
+				t.Errorf("expected every syncer with its provisioning capabilities %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestConnectorProvisioningCapabilities(t *testing.T) {
+	tests := []struct {
+		name        string
+		scopes      []string
+		readOnly    bool
+		wantSyncers []string
+	}{
+		{
+			name:        "every write scope",
+			scopes:      outreachtest.DefaultScopes,
+			wantSyncers: []string{"user", "team", "profile", "role", "mailbox", "sequence"},
+		},
+		{
+			name:   "users can't be written",
+			scopes: []string{"users.read", "teams.all", "profiles.read", "roles.read", "mailboxes.read", "sequences.all"},
+			wantSyncers: []string{
+				"user (read-only)", "team", "profile (read-only)", "role (read-only)", "mailbox", "sequence",
+			},
+		},
+		{
+			name:   "only read scopes",
+			scopes: []string{"users.read", "teams.read", "profiles.read", "roles.read", "mailboxes.read", "sequences.read"},
+			wantSyncers: []string{
+				"user (read-only)", "team (read-only)", "profile (read-only)", "role (read-only)", "mailbox", "sequence (read-only)",
+			},
+		},
+		{
+			name:     "read-only mode",
+			scopes:   outreachtest.DefaultScopes,
+			readOnly: true,
+			wantSyncers: []string{
+				"user (read-only)", "team (read-only)", "profile (read-only)", "role (read-only)", "mailbox (read-only)", "sequence (read-only)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			_, d := newTestConnector(t, tt.scopes, WithReadOnly(tt.readOnly))
+
+			if got := syncerTypes(ctx, d.ResourceSyncers(ctx)); !slices.Equal(got, tt.wantSyncers) {
+				t.Errorf("expected the syncers %v, got %v", tt.wantSyncers, got)
+			}
+
+			// The write scopes of the hidden provisioning capabilities are not required.
+			_, err := d.Validate(ctx)
+			checkCode(t, err, codes.OK)
+		})
+	}
+}
+
+func TestRequiredScopes(t *testing.T) {
+	want := []string{"users.read", "teams.read", "profiles.read", "mailboxes.read", "roles.read", "sequences.read"}
+	if got := RequiredScopes(true); !slices.Equal(got, want) {
+		t.Errorf("expected the read-only scopes %v, got %v", want, got)
+	}
+
+	want = append(want, "sequences.write", "teams.write", "users.write")
+	if got := RequiredScopes(false); !slices.Equal(got, want) {
+		t.Errorf("expected the scopes %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// appJWTLifetime is how long the JWTs signed for the app are valid, they are only used once for the exchange.
+	appJWTLifetime = 5 * time.Minute
+	// appJWTClockSkew backdates the JWTs, so they are not rejected because of clock differences with Outreach.
+	appJWTClockSkew = time.Minute
+)
+
+// AppConfig identifies an installation of an Outreach server-to-server (S2S) app.
+type AppConfig struct {
+	AppID          string
+	InstallationID string
+	// PrivateKey is the PEM encoded RSA private key of the app, in PKCS #1 or PKCS #8 form.
+	PrivateKey []byte
+	// BaseURL overrides the base URL of the Outreach API when set. The app endpoints are found next to it.
+	BaseURL string
+}
+
+// appTokenSource gets installation access tokens for an S2S app. A JWT signed with the private key of the app
+// is exchanged for an access token bound to the installation instead of a user, so no refresh token is needed.
+type appTokenSource struct {
+	ctx      context.Context
+	appID    string
+	signer   jose.Signer
+	tokenURL string
+}
+
+type appAccessTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// NewAppTokenSource returns a TokenSource of installation access tokens for the S2S app.
+// Tokens are reused until they expire.
+func NewAppTokenSource(ctx context.Context, config AppConfig) (oauth2.TokenSource, error) {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	apiURL, err := ValidateURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenSource, err := newAppTokenSource(ctx, config, resolveURL(apiURL, appInstallsRef))
+	if err != nil {
+		return nil, err
+	}
+
+	return oauth2.ReuseTokenSource(nil, tokenSource), nil
+}
+
+func newAppTokenSource(ctx context.Context, config AppConfig, installsURL string) (*appTokenSource, error) {
+	if config.AppID == "" || config.InstallationID == "" {
+		return nil, errors.New("outreach: the app ID and the installation ID are required for app authentication")
+	}
+
+	privateKey, err := parseRSAPrivateKey(config.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: privateKey}, (&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		return nil, err
+	}
+
+	tokenURL, err := url.JoinPath(installsURL, config.InstallationID, "actions", "accessToken")
+	if err != nil {
+		return nil, err
+	}
+
+	return &appTokenSource{
+		ctx:      ctx,
+		appID:    config.AppID,
+		signer:   signer,
+		tokenURL: tokenURL,
+	}, nil
+}
+
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	now := time.Now()
+	assertion, err := jwt.Signed(s.signer).Claims(jwt.Claims{
+		Issuer:   s.appID,
+		IssuedAt: jwt.NewNumericDate(now.Add(-appJWTClockSkew)),
+		Expiry:   jwt.NewNumericDate(now.Add(appJWTLifetime)),
+	}).Serialize()
+	if err != nil {
+		return nil, fmt.Errorf("outreach: unable to sign the app JWT: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, s.tokenURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+assertion)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := oauthHTTPClient(s.ctx).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("outreach: unable to get an installation access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("outreach: unable to get an installation access token: %w", newAPIError(resp, nil))
+	}
+
+	var response appAccessTokenResponse
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, fmt.Errorf("outreach: unable to parse the installation access token: %w", err)
+	}
+
+	if response.Token == "" {
+		return nil, errors.New("outreach: no installation access token was issued")
+	}
+
+	return &oauth2.Token{
+		AccessToken: response.Token,
+		TokenType:   "Bearer",
+		Expiry:      response.ExpiresAt,
+	}, nil
+}
+
+// oauthHTTPClient returns the client set on the context for the OAuth requests, like the oauth2 package does.
+func oauthHTTPClient(ctx context.Context) *http.Client {
+	if httpClient, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok && httpClient != nil {
+		return httpClient
+	}
+
+	return http.DefaultClient
+}
+
+func parseRSAPrivateKey(pemBytes []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("outreach: the app private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("outreach: unable to parse the app private key: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("outreach: the app private key is not an RSA key")
+	}
+
+	return rsaKey, nil
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+func TestAppTokenSource(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/installs/42/actions/accessToken" {
+			http.Error(w, "unexpected request", http.StatusNotFound)
+			return
+		}
+
+		token, err := jwt.ParseSigned(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), []jose.SignatureAlgorithm{jose.RS256})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var claims jwt.Claims
+		if err = token.Claims(&privateKey.PublicKey, &claims); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err = claims.Validate(jwt.Expected{Issuer: "app-id", Time: time.Now()}); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(appAccessTokenResponse{
+			Token:     "installation-token",
+			ExpiresAt: expiresAt,
+		})
+	}))
+	defer server.Close()
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	tokenSource, err := newAppTokenSource(context.Background(), AppConfig{
+		AppID:          "app-id",
+		InstallationID: "42",
+		PrivateKey:     keyPEM,
+	}, server.URL+"/installs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := tokenSource.Token()
+	if err != nil {
+		t.Fatalf("unable to get the installation token: %v", err)
+	}
+
+	if token.AccessToken != "installation-token" || !token.Expiry.Equal(expiresAt) {
+		t.Errorf("unexpected token: %+v", token)
+	}
+}
+
+func TestAppTokenSourceRejectsInvalidKey(t *testing.T) {
+	_, err := NewAppTokenSource(context.Background(), AppConfig{
+		AppID:          "app-id",
+		InstallationID: "42",
+		PrivateKey:     []byte("not a key"),
+	})
+	if err == nil {
+		t.Fatal("expected an invalid private key error")
+	}
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+)
+
+const (
+	batchesEP    = "batches"
+	batchItemsEP = "batchItems"
+
+	batchItemsFilter = "filter[batch][id]"
+
+	// BatchActionUpdate updates every record of the batch, like a PATCH of each of them.
+	BatchActionUpdate = "update"
+
+	BatchStatePending    = "pending"
+	BatchStateProcessing = "processing"
+	BatchStateComplete   = "complete"
+
+	BatchItemStateSuccess = "success"
+	BatchItemStateError   = "error"
+
+	// batchTimeout is how long a batch is waited for before giving up on its results.
+	batchTimeout = 10 * time.Minute
+)
+
+type BatchAttributes struct {
+	Action     string           `json:"action"`
+	RecordType string           `json:"recordType"`
+	Records    []ResourceObject `json:"records,omitempty"`
+	State      string           `json:"state,omitempty"`
+	CreatedAt  string           `json:"createdAt,omitempty"`
+	UpdatedAt  string           `json:"updatedAt,omitempty"`
+}
+
+type Batch struct {
+	Id         int             `json:"id"`
+	Type       string          `json:"type"` // Type should always be 'batch'.
+	Attributes BatchAttributes `json:"attributes"`
+}
+
+type BatchItemAttributes struct {
+	RecordId     int    `json:"recordId"`
+	RecordType   string `json:"recordType"`
+	State        string `json:"state"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+type BatchItem struct {
+	Id         int                 `json:"id"`
+	Type       string              `json:"type"`
+	Attributes BatchItemAttributes `json:"attributes"`
+}
+
+// CreateBatch submits the records to the batch endpoint, which applies the action to each of them asynchronously.
+func (c *OutreachClient) CreateBatch(ctx context.Context, recordType string, records []ResourceObject) (*Batch, *v2.RateLimitDescription, error) {
+	newBatch := Batch{
+		Type: "batch",
+		Attributes: BatchAttributes{
+			Action:     BatchActionUpdate,
+			RecordType: recordType,
+			Records:    records,
+		},
+	}
+
+	response, rateLimitDescription, err := Create[Batch](ctx, c, newBatch, batchesEP)
+	if err != nil {
+		return nil, rateLimitDescription, err
+	}
+
+	return response.Data, rateLimitDescription, nil
+}
+
+func (c *OutreachClient) GetBatch(ctx context.Context, batchID int) (*Batch, *v2.RateLimitDescription, error) {
+	response, rateLimitDescription, err := Get[Batch](ctx, c, nil, batchesEP, strconv.Itoa(batchID))
+	if err != nil {
+		return nil, rateLimitDescription, err
+	}
+
+	return response.Data, rateLimitDescription, nil
+}
+
+// ListAllBatchItems requests a page of the items of a batch, which hold the result for each of its records.
+func (c *OutreachClient) ListAllBatchItems(ctx context.Context, batchID int, cursor string) ([]*BatchItem, string, *v2.RateLimitDescription, error) {
+	query := url.Values{}
+	query.Set(batchItemsFilter, strconv.Itoa(batchID))
+
+	response, nextCursor, rateLimitDescription, err := List[BatchItem](ctx, c, cursor, query, batchItemsEP)
+	if err != nil {
+		return nil, "", rateLimitDescription, err
+	}
+
+	return response.Data, nextCursor, rateLimitDescription, nil
+}
+
+// RunBatch submits the records as a batch, polls it until it is complete and returns the result of each record by ID.
+// The batch is read with the provisioning context, so its state is never served from the cache.
+func (c *OutreachClient) RunBatch(
+	ctx context.Context,
+	recordType string,
+	records []ResourceObject,
+	pollInterval time.Duration,
+) (map[int]*BatchItem, *v2.RateLimitDescription, error) {
+	ctx, cancel := context.WithTimeout(ContextWithProvisioning(ctx), batchTimeout)
+	defer cancel()
+
+	batch, rateLimitDescription, err := c.CreateBatch(ctx, recordType, records)
+	if err != nil {
+		return nil, rateLimitDescription, err
+	}
+
+	for batch.Attributes.State != BatchStateComplete {
+		timer := time.NewTimer(pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, rateLimitDescription, fmt.Errorf("outreach: the batch %d is still %s: %w", batch.Id, batch.Attributes.State, ctx.Err())
+		case <-timer.C:
+		}
+
+		batch, rateLimitDescription, err = c.GetBatch(ctx, batch.Id)
+		if err != nil {
+			return nil, rateLimitDescription, err
+		}
+	}
+
+	items := make(map[int]*BatchItem, len(records))
+	var cursor string
+	for {
+		page, nextCursor, rateLimitDescription, err := c.ListAllBatchItems(ctx, batch.Id, cursor)
+		if err != nil {
+			return nil, rateLimitDescription, err
+		}
+
+		for _, item := range page {
+			items[item.Attributes.RecordId] = item
+		}
+
+		if nextCursor == "" {
+			return items, rateLimitDescription, nil
+		}
+		cursor = nextCursor
+	}
+}
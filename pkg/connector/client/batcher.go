@@ -0,0 +1,411 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultBatchMaxSize is the number of changes submitted in a single batch by default.
+	DefaultBatchMaxSize = 100
+	// DefaultBatchPollInterval is how often the state of a submitted batch is requested by default.
+	DefaultBatchPollInterval = 2 * time.Second
+)
+
+// BatchOptions configures how the provisioning changes are collected into batches.
+type BatchOptions struct {
+	// Window is how long the changes are collected before being submitted together. Batching is disabled when it is 0.
+	Window time.Duration
+	// MaxSize submits the collected changes as soon as there are that many of them.
+	MaxSize int
+	// PollInterval is how often the state of a submitted batch is requested.
+	PollInterval time.Duration
+}
+
+// WithBatching collects the team membership and profile changes made within the window, and submits them together
+// through the Outreach batch endpoint instead of updating the resources one at a time.
+func WithBatching(options BatchOptions) ConfigOption {
+	return func(client *OutreachClient) {
+		if options.Window <= 0 {
+			client.batcher = nil
+			return
+		}
+
+		if options.MaxSize <= 0 {
+			options.MaxSize = DefaultBatchMaxSize
+		}
+		if options.PollInterval <= 0 {
+			options.PollInterval = DefaultBatchPollInterval
+		}
+
+		client.batcher = newBatcher(client, options)
+	}
+}
+
+type batchResult struct {
+	changed bool
+	err     error
+}
+
+type profileChange struct {
+	profileID int
+	result    chan batchResult
+}
+
+type membershipChange struct {
+	userID int
+	member bool
+	result chan batchResult
+}
+
+// pendingBatch holds the changes collected during a window. Each user has at most one pending change of its profile,
+// and of its membership in each team.
+type pendingBatch struct {
+	// ctx is the context of the first change, without its cancellation, since the batch outlives that request.
+	ctx         context.Context
+	profiles    map[int]*profileChange
+	memberships map[string][]*membershipChange
+	size        int
+}
+
+// batcher coalesces the provisioning changes and submits them through the batch endpoint, reporting the result of each
+// record back to the caller that made the change.
+type batcher struct {
+	client  *OutreachClient
+	options BatchOptions
+
+	mu      sync.Mutex
+	pending *pendingBatch
+	timer   *time.Timer
+}
+
+func newBatcher(client *OutreachClient, options BatchOptions) *batcher {
+	return &batcher{
+		client:  client,
+		options: options,
+	}
+}
+
+// Batching reports whether the provisioning changes are submitted through the batch endpoint.
+func (c *OutreachClient) Batching() bool {
+	return c.batcher != nil
+}
+
+// BatchUpdateUserProfile changes the profile of the user within the next batch, and waits for its result.
+// Changes that are overwritten by a concurrent update fail with codes.Aborted.
+func (c *OutreachClient) BatchUpdateUserProfile(ctx context.Context, userID string, profileID int) error {
+	numericUserID, err := strconv.Atoi(userID)
+	if err != nil {
+		return err
+	}
+
+	result := c.batcher.queueProfile(ctx, numericUserID, profileID)
+
+	select {
+	case r := <-result:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// BatchUpdateTeamMembership adds the user to the team or removes it from the team within the next batch, and reports
+// whether the members had to be changed. Changes that are overwritten by a concurrent update fail with codes.Aborted.
+func (c *OutreachClient) BatchUpdateTeamMembership(ctx context.Context, teamID string, userID int, member bool) (bool, error) {
+	result := c.batcher.queueMembership(ctx, teamID, userID, member)
+
+	select {
+	case r := <-result:
+		return r.changed, r.err
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+func (b *batcher) queueProfile(ctx context.Context, userID, profileID int) <-chan batchResult {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// A second change of the same user can't be part of the same batch, since only one of them would be applied.
+	if b.pending != nil && b.pending.profiles[userID] != nil {
+		b.flushLocked()
+	}
+
+	result := make(chan batchResult, 1)
+	pending := b.pendingLocked(ctx)
+	pending.profiles[userID] = &profileChange{profileID: profileID, result: result}
+	b.addedLocked(pending)
+
+	return result
+}
+
+func (b *batcher) queueMembership(ctx context.Context, teamID string, userID int, member bool) <-chan batchResult {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pending != nil && slices.ContainsFunc(b.pending.memberships[teamID], func(change *membershipChange) bool {
+		return change.userID == userID
+	}) {
+		b.flushLocked()
+	}
+
+	result := make(chan batchResult, 1)
+	pending := b.pendingLocked(ctx)
+	pending.memberships[teamID] = append(pending.memberships[teamID], &membershipChange{userID: userID, member: member, result: result})
+	b.addedLocked(pending)
+
+	return result
+}
+
+// pendingLocked returns the batch collecting the changes, starting a new window when there is none.
+func (b *batcher) pendingLocked(ctx context.Context) *pendingBatch {
+	if b.pending != nil {
+		return b.pending
+	}
+
+	pending := &pendingBatch{
+		ctx:         context.WithoutCancel(ctx),
+		profiles:    make(map[int]*profileChange),
+		memberships: make(map[string][]*membershipChange),
+	}
+	b.pending = pending
+	b.timer = time.AfterFunc(b.options.Window, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		// The batch may have been submitted already, once it was full.
+		if b.pending == pending {
+			b.flushLocked()
+		}
+	})
+
+	return pending
+}
+
+func (b *batcher) addedLocked(pending *pendingBatch) {
+	pending.size++
+	if pending.size >= b.options.MaxSize {
+		b.flushLocked()
+	}
+}
+
+// flushLocked submits the pending changes in the background, so new changes start the next batch.
+func (b *batcher) flushLocked() {
+	pending := b.pending
+	if pending == nil {
+		return
+	}
+
+	b.pending = nil
+	b.timer.Stop()
+
+	go b.submit(pending)
+}
+
+func (b *batcher) submit(pending *pendingBatch) {
+	ctx := ContextWithProvisioning(pending.ctx)
+
+	var wg sync.WaitGroup
+	if len(pending.profiles) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.submitProfiles(ctx, pending.profiles)
+		}()
+	}
+	if len(pending.memberships) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.submitMemberships(ctx, pending.memberships)
+		}()
+	}
+	wg.Wait()
+}
+
+func (b *batcher) submitProfiles(ctx context.Context, changes map[int]*profileChange) {
+	records := make([]ResourceObject, 0, len(changes))
+	for userID, change := range changes {
+		profile, err := json.Marshal(DataDetailPair{Id: change.profileID, Type: "profile"})
+		if err != nil {
+			change.result <- batchResult{err: err}
+			delete(changes, userID)
+			continue
+		}
+
+		records = append(records, ResourceObject{
+			Id:            userID,
+			Type:          "user",
+			Relationships: map[string]Relationship{"profile": {Data: profile}},
+		})
+	}
+
+	if len(records) == 0 {
+		return
+	}
+
+	items, _, err := b.client.RunBatch(ctx, "user", records, b.options.PollInterval)
+	for userID, change := range changes {
+		changeErr := err
+		if changeErr == nil {
+			changeErr = batchItemError(items[userID], "user", userID)
+		}
+		if changeErr == nil {
+			changeErr = b.verifyProfile(ctx, userID, change.profileID)
+		}
+
+		change.result <- batchResult{changed: changeErr == nil, err: changeErr}
+	}
+}
+
+// verifyProfile reads the user again once the batch is complete, since an update made at the same time by another
+// process can overwrite the batched one. Overwritten changes fail with codes.Aborted.
+func (b *batcher) verifyProfile(ctx context.Context, userID, profileID int) error {
+	user, _, err := b.client.GetUserByID(ctx, strconv.Itoa(userID))
+	if err != nil {
+		return err
+	}
+
+	if user.Relationships != nil && user.Relationships.Profile != nil && user.Relationships.Profile.Data != nil &&
+		user.Relationships.Profile.Data.Id == profileID {
+		return nil
+	}
+
+	ctxzap.Extract(ctx).Warn(
+		"the batched update of the user profile was overwritten",
+		zap.Int("user_id", userID),
+		zap.Int("profile_id", profileID),
+	)
+
+	return status.Errorf(
+		codes.Aborted,
+		"outreach: the batched update of the profile of the user {%d} was overwritten before it was moved to the profile {%d}",
+		userID,
+		profileID,
+	)
+}
+
+// submitMemberships submits the complete list of members of each changed team, built from its current members and
+// all of its pending changes, and verifies that the changes hold once the batch is complete. The teams stay locked
+// until then, so the updates made on their own by this process can't overwrite the batch, nor be overwritten by it.
+func (b *batcher) submitMemberships(ctx context.Context, changes map[string][]*membershipChange) {
+	logger := ctxzap.Extract(ctx)
+
+	unlock := b.client.LockTeams(slices.Collect(maps.Keys(changes))...)
+	defer unlock()
+
+	submitted := make(map[int][]*membershipChange, len(changes))
+	records := make([]ResourceObject, 0, len(changes))
+	for teamID, teamChanges := range changes {
+		numericTeamID, err := strconv.Atoi(teamID)
+		if err != nil {
+			resolveMemberships(teamChanges, batchResult{err: err})
+			continue
+		}
+
+		teamMembers, _, err := b.client.GetTeamMembers(ctx, teamID)
+		if err != nil {
+			resolveMemberships(teamChanges, batchResult{err: err})
+			continue
+		}
+
+		var pending []*membershipChange
+		for _, change := range teamChanges {
+			if IsTeamMember(teamMembers, change.userID) == change.member {
+				change.result <- batchResult{}
+				continue
+			}
+
+			teamMembers = WithTeamMember(teamMembers, change.userID, change.member)
+			pending = append(pending, change)
+		}
+
+		if len(pending) == 0 {
+			continue
+		}
+
+		users, err := json.Marshal(teamMembers)
+		if err != nil {
+			resolveMemberships(pending, batchResult{err: err})
+			continue
+		}
+
+		submitted[numericTeamID] = pending
+		records = append(records, ResourceObject{
+			Id:            numericTeamID,
+			Type:          "team",
+			Relationships: map[string]Relationship{"users": {Data: users}},
+		})
+	}
+
+	if len(records) == 0 {
+		return
+	}
+
+	items, _, err := b.client.RunBatch(ctx, "team", records, b.options.PollInterval)
+	for teamID, pending := range submitted {
+		if err != nil {
+			resolveMemberships(pending, batchResult{err: err})
+			continue
+		}
+
+		if itemErr := batchItemError(items[teamID], "team", teamID); itemErr != nil {
+			resolveMemberships(pending, batchResult{err: itemErr})
+			continue
+		}
+
+		teamMembers, _, readErr := b.client.GetTeamMembers(ctx, strconv.Itoa(teamID))
+		if readErr != nil {
+			resolveMemberships(pending, batchResult{err: readErr})
+			continue
+		}
+
+		for _, change := range pending {
+			if IsTeamMember(teamMembers, change.userID) == change.member {
+				change.result <- batchResult{changed: true}
+				continue
+			}
+
+			logger.Warn(
+				"the batched update of the team members was overwritten",
+				zap.Int("team_id", teamID),
+				zap.Int("user_id", change.userID),
+			)
+			change.result <- batchResult{err: status.Errorf(
+				codes.Aborted,
+				"outreach: the batched update of the members of the team {%d} was overwritten before the user {%d} was changed",
+				teamID,
+				change.userID,
+			)}
+		}
+	}
+}
+
+func resolveMemberships(changes []*membershipChange, result batchResult) {
+	for _, change := range changes {
+		change.result <- result
+	}
+}
+
+// batchItemError returns the error of the record within the batch, if it wasn't applied.
+func batchItemError(item *BatchItem, recordType string, recordID int) error {
+	if item == nil {
+		return status.Errorf(codes.Unknown, "outreach: the batch returned no result for the %s {%d}", recordType, recordID)
+	}
+
+	if item.Attributes.State != BatchItemStateSuccess {
+		return status.Errorf(codes.Unknown, "outreach: the batch failed to update the %s {%d}: %s", recordType, recordID, item.Attributes.ErrorMessage)
+	}
+
+	return nil
+}
@@ -1,99 +1,138 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
-	authURL    = "https://api.outreach.io/oauth/token"
-	baseURL    = "https://api.outreach.io/api/v2"
-	usersEP    = "users"
-	teamsEP    = "teams"
-	profilesEP = "profiles"
+	usersEP     = "users"
+	teamsEP     = "teams"
+	profilesEP  = "profiles"
+	rolesEP     = "roles"
+	mailboxesEP = "mailboxes"
+	sequencesEP = "sequences"
+
+	usersIncludedRelationships = "profile,teams,manager"
+	// usersRoleRelationship is only included when the roles are synced, since it needs the roles.read scope.
+	usersRoleRelationship = "role"
+
+	teamMembersFilter = "filter[teams][id]"
+	childRolesFilter  = "filter[parentRole][id]"
 )
 
+// UpdatedAtRange limits a list request to the resources updated within the range.
+// A zero bound leaves that side of the range open, and a zero range doesn't filter at all.
+type UpdatedAtRange struct {
+	From time.Time
+	To   time.Time
+}
+
+func (r UpdatedAtRange) apply(query url.Values) {
+	if r.From.IsZero() && r.To.IsZero() {
+		return
+	}
+
+	from, to := "neginf", "inf"
+	if !r.From.IsZero() {
+		from = r.From.UTC().Format(time.RFC3339)
+	}
+	if !r.To.IsZero() {
+		to = r.To.UTC().Format(time.RFC3339)
+	}
+
+	query.Set("filter[updatedAt]", from+".."+to)
+}
+
 type OutreachClient struct {
 	client      *uhttp.BaseHttpClient
 	TokenSource oauth2.TokenSource
+	rateLimiter *rateLimiter
+	pageSize    int
+	tokenStore  TokenStore
+	// refreshCredentials are set by WithRefreshToken, and turned into the token source by New.
+	refreshCredentials *refreshCredentials
+	baseURL            string
+	tokenURL           string
+	// apiURL is the parsed base URL, the origin of every request carrying the token.
+	apiURL *url.URL
+	// httpClient is used for the API requests and the token refreshes, instead of the default one of the SDK.
+	httpClient *http.Client
+	// batcher collects the provisioning changes into batches, when batching is enabled.
+	batcher   *batcher
+	teamLocks *teamLocks
 }
 
-func (c *OutreachClient) ListAllUsers(ctx context.Context, nextPageLink string) ([]*User, string, *v2.RateLimitDescription, error) {
-	var (
-		requestURL string
-		response   UsersResponse
-	)
-
-	if nextPageLink != "" {
-		requestURL = nextPageLink
-	} else {
-		usersURL, err := url.JoinPath(baseURL, usersEP)
-		if err != nil {
-			return nil, "", nil, err
-		}
-
-		requestURL = usersURL
+// ListAllUsers lists the users with their relationships. The role is only included when includeRole is set, since
+// including it fails without the roles.read scope.
+func (c *OutreachClient) ListAllUsers(
+	ctx context.Context,
+	cursor string,
+	updatedAt UpdatedAtRange,
+	includeRole bool,
+) ([]*User, string, *v2.RateLimitDescription, error) {
+	// Including the relationships on the list request lets the grants be built from the listed page,
+	// instead of requesting every user again.
+	include := usersIncludedRelationships
+	if includeRole {
+		include += "," + usersRoleRelationship
+	}
+
+	query := url.Values{}
+	query.Set("include", include)
+	updatedAt.apply(query)
+
+	response, nextCursor, rateLimitDescription, err := List[User](ctx, c, cursor, query, usersEP)
+	if err != nil {
+		return nil, "", rateLimitDescription, err
 	}
 
-	rateLimitDescription := &v2.RateLimitDescription{}
-	_, err := c.doRequest(
-		ctx,
-		http.MethodGet,
-		requestURL,
-		&response,
-		nil,
-		rateLimitDescription,
-	)
+	// The managers listed on the same page aren't included again, so they are looked up in both.
+	includedUsers, err := IncludedOfType[User](response.Included, "user")
 	if err != nil {
 		return nil, "", rateLimitDescription, err
 	}
 
-	var nextLink string
-	if response.Links != nil {
-		nextLink = response.Links.Next
+	usersByID := make(map[int]*User, len(response.Data)+len(includedUsers))
+	for _, user := range append(includedUsers, response.Data...) {
+		usersByID[user.Id] = user
 	}
 
-	return response.Results, nextLink, rateLimitDescription, nil
-}
-
-func (c *OutreachClient) GetUserByID(ctx context.Context, userID string) (*User, *v2.RateLimitDescription, error) {
-	var response struct {
-		User *User `json:"data"`
+	for _, user := range response.Data {
+		if managerID := user.ManagerID(); managerID != 0 {
+			user.Manager = usersByID[managerID]
+		}
 	}
 
-	userURL, err := url.JoinPath(baseURL, usersEP, userID)
-	if err != nil {
-		return nil, nil, err
-	}
+	return response.Data, nextCursor, rateLimitDescription, nil
+}
 
-	rateLimitDescription := &v2.RateLimitDescription{}
-	_, err = c.doRequest(
-		ctx,
-		http.MethodGet,
-		userURL,
-		&response,
-		nil,
-		rateLimitDescription,
-	)
+func (c *OutreachClient) GetUserByID(ctx context.Context, userID string) (*User, *v2.RateLimitDescription, error) {
+	response, rateLimitDescription, err := Get[User](ctx, c, nil, usersEP, userID)
 	if err != nil {
 		return nil, rateLimitDescription, err
 	}
 
-	return response.User, rateLimitDescription, nil
+	return response.Data, rateLimitDescription, nil
 }
 
 func (c *OutreachClient) UpdateUserProfile(ctx context.Context, userID string, profileID int) (*v2.RateLimitDescription, error) {
-	var requestBody struct {
-		Data UpdateUsersProfileBody `json:"data"`
-	}
-
 	newProfile := DataDetailPair{
 		Id:   profileID,
 		Type: "profile",
@@ -104,7 +143,7 @@ func (c *OutreachClient) UpdateUserProfile(ctx context.Context, userID string, p
 		return nil, err
 	}
 
-	requestBody.Data = UpdateUsersProfileBody{
+	requestBody := UpdateUsersProfileBody{
 		Id:   numericUserID,
 		Type: "user",
 		Relationships: UserProfileRelationships{
@@ -116,20 +155,7 @@ func (c *OutreachClient) UpdateUserProfile(ctx context.Context, userID string, p
 		},
 	}
 
-	userURL, err := url.JoinPath(baseURL, usersEP, userID)
-	if err != nil {
-		return nil, err
-	}
-
-	rateLimitDescription := &v2.RateLimitDescription{}
-	_, err = c.doRequest(
-		ctx,
-		http.MethodPatch,
-		userURL,
-		nil,
-		requestBody,
-		rateLimitDescription,
-	)
+	_, rateLimitDescription, err := Patch[User](ctx, c, requestBody, usersEP, userID)
 	if err != nil {
 		return rateLimitDescription, err
 	}
@@ -138,16 +164,12 @@ func (c *OutreachClient) UpdateUserProfile(ctx context.Context, userID string, p
 }
 
 func (c *OutreachClient) DisableUser(ctx context.Context, userID string) (*v2.RateLimitDescription, error) {
-	var requestBody struct {
-		Data UserLockStatusUpdate `json:"data"`
-	}
-
 	numericUserID, err := strconv.Atoi(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	requestBody.Data = UserLockStatusUpdate{
+	requestBody := UserLockStatusUpdate{
 		Id:   numericUserID,
 		Type: "user",
 		Attributes: struct {
@@ -157,20 +179,7 @@ func (c *OutreachClient) DisableUser(ctx context.Context, userID string) (*v2.Ra
 		},
 	}
 
-	userURL, err := url.JoinPath(baseURL, usersEP, userID)
-	if err != nil {
-		return nil, err
-	}
-
-	rateLimitDescription := &v2.RateLimitDescription{}
-	_, err = c.doRequest(
-		ctx,
-		http.MethodPatch,
-		userURL,
-		nil,
-		requestBody,
-		rateLimitDescription,
-	)
+	_, rateLimitDescription, err := Patch[User](ctx, c, requestBody, usersEP, userID)
 	if err != nil {
 		return rateLimitDescription, err
 	}
@@ -179,144 +188,208 @@ func (c *OutreachClient) DisableUser(ctx context.Context, userID string) (*v2.Ra
 }
 
 func (c *OutreachClient) CreateUser(ctx context.Context, newUserInfo NewUserBody) (*User, *v2.RateLimitDescription, error) {
-	var response struct {
-		User *User `json:"data"`
+	response, rateLimitDescription, err := Create[User](ctx, c, newUserInfo.Data, usersEP)
+	if err != nil {
+		return nil, rateLimitDescription, err
 	}
 
-	userURL, err := url.JoinPath(baseURL, usersEP)
+	return response.Data, rateLimitDescription, nil
+}
+
+func (c *OutreachClient) ListAllTeams(
+	ctx context.Context,
+	cursor string,
+	updatedAt UpdatedAtRange,
+) ([]*Team, string, *v2.RateLimitDescription, error) {
+	query := url.Values{}
+	updatedAt.apply(query)
+
+	response, nextCursor, rateLimitDescription, err := List[Team](ctx, c, cursor, query, teamsEP)
 	if err != nil {
-		return nil, nil, err
+		return nil, "", rateLimitDescription, err
 	}
 
-	rateLimitDescription := &v2.RateLimitDescription{}
-	_, err = c.doRequest(
-		ctx,
-		http.MethodPost,
-		userURL,
-		&response,
-		newUserInfo,
-		rateLimitDescription,
-	)
+	return response.Data, nextCursor, rateLimitDescription, nil
+}
+
+func (c *OutreachClient) GetTeamByID(ctx context.Context, teamID string) (*Team, *v2.RateLimitDescription, error) {
+	response, rateLimitDescription, err := Get[Team](ctx, c, nil, teamsEP, teamID)
 	if err != nil {
 		return nil, rateLimitDescription, err
 	}
 
-	return response.User, rateLimitDescription, nil
+	return response.Data, rateLimitDescription, nil
+}
+
+// ListAllTeamMembers requests a page of the members of a team. Outreach caps the users embedded in the relationships
+// of a team, so the members are listed from the users filtered by team instead.
+func (c *OutreachClient) ListAllTeamMembers(
+	ctx context.Context,
+	teamID string,
+	cursor string,
+) ([]*User, string, *v2.RateLimitDescription, error) {
+	query := url.Values{}
+	query.Set(teamMembersFilter, teamID)
+
+	response, nextCursor, rateLimitDescription, err := List[User](ctx, c, cursor, query, usersEP)
+	if err != nil {
+		return nil, "", rateLimitDescription, err
+	}
+
+	return response.Data, nextCursor, rateLimitDescription, nil
 }
 
-func (c *OutreachClient) ListAllTeams(ctx context.Context, nextPageLink string) ([]*Team, string, *v2.RateLimitDescription, error) {
+// GetTeamMembers returns every member of a team, requesting all the pages of ListAllTeamMembers.
+func (c *OutreachClient) GetTeamMembers(ctx context.Context, teamID string) ([]DataDetailPair, *v2.RateLimitDescription, error) {
 	var (
-		requestURL string
-		response   TeamsResponse
+		members []DataDetailPair
+		cursor  string
 	)
 
-	if nextPageLink != "" {
-		requestURL = nextPageLink
-	} else {
-		teamsURL, err := url.JoinPath(baseURL, teamsEP)
+	for {
+		users, nextCursor, rateLimitDescription, err := c.ListAllTeamMembers(ctx, teamID, cursor)
 		if err != nil {
-			return nil, "", nil, err
+			return nil, rateLimitDescription, err
 		}
 
-		requestURL = teamsURL
+		for _, user := range users {
+			members = append(members, DataDetailPair{
+				Id:   user.Id,
+				Type: "user",
+			})
+		}
+
+		if nextCursor == "" {
+			return members, rateLimitDescription, nil
+		}
+		cursor = nextCursor
 	}
+}
 
-	rateLimitDescription := &v2.RateLimitDescription{}
-	_, err := c.doRequest(
-		ctx,
-		http.MethodGet,
-		requestURL,
-		&response,
-		nil,
-		rateLimitDescription,
-	)
+func (c *OutreachClient) ListAllProfiles(
+	ctx context.Context,
+	cursor string,
+	updatedAt UpdatedAtRange,
+) ([]*Profile, string, *v2.RateLimitDescription, error) {
+	query := url.Values{}
+	updatedAt.apply(query)
+
+	response, nextCursor, rateLimitDescription, err := List[Profile](ctx, c, cursor, query, profilesEP)
+	if err != nil {
+		return nil, "", rateLimitDescription, err
+	}
+
+	return response.Data, nextCursor, rateLimitDescription, nil
+}
+
+// ListAllRoles requests a page of the roles. With a parent role, only its direct children are requested.
+func (c *OutreachClient) ListAllRoles(ctx context.Context, cursor, parentRoleID string) ([]*Role, string, *v2.RateLimitDescription, error) {
+	query := url.Values{}
+	if parentRoleID != "" {
+		query.Set(childRolesFilter, parentRoleID)
+	}
+
+	response, nextCursor, rateLimitDescription, err := List[Role](ctx, c, cursor, query, rolesEP)
 	if err != nil {
 		return nil, "", rateLimitDescription, err
 	}
 
-	var nextLink string
-	if response.Links != nil {
-		nextLink = response.Links.Next
+	return response.Data, nextCursor, rateLimitDescription, nil
+}
+
+func (c *OutreachClient) ListAllMailboxes(ctx context.Context, cursor string) ([]*Mailbox, string, *v2.RateLimitDescription, error) {
+	response, nextCursor, rateLimitDescription, err := List[Mailbox](ctx, c, cursor, url.Values{}, mailboxesEP)
+	if err != nil {
+		return nil, "", rateLimitDescription, err
 	}
 
-	return response.Results, nextLink, rateLimitDescription, nil
+	return response.Data, nextCursor, rateLimitDescription, nil
 }
 
-func (c *OutreachClient) GetTeamByID(ctx context.Context, teamID string) (*Team, *v2.RateLimitDescription, error) {
-	var response struct {
-		Team *Team `json:"data"`
+func (c *OutreachClient) GetMailboxByID(ctx context.Context, mailboxID string) (*Mailbox, *v2.RateLimitDescription, error) {
+	response, rateLimitDescription, err := Get[Mailbox](ctx, c, nil, mailboxesEP, mailboxID)
+	if err != nil {
+		return nil, rateLimitDescription, err
 	}
 
-	teamURL, err := url.JoinPath(baseURL, teamsEP, teamID)
+	return response.Data, rateLimitDescription, nil
+}
+
+func (c *OutreachClient) ListAllSequences(ctx context.Context, cursor string) ([]*Sequence, string, *v2.RateLimitDescription, error) {
+	response, nextCursor, rateLimitDescription, err := List[Sequence](ctx, c, cursor, url.Values{}, sequencesEP)
 	if err != nil {
-		return nil, nil, err
+		return nil, "", rateLimitDescription, err
 	}
 
-	rateLimitDescription := &v2.RateLimitDescription{}
-	_, err = c.doRequest(
-		ctx,
-		http.MethodGet,
-		teamURL,
-		&response,
-		nil,
-		rateLimitDescription,
-	)
+	return response.Data, nextCursor, rateLimitDescription, nil
+}
+
+func (c *OutreachClient) GetSequenceByID(ctx context.Context, sequenceID string) (*Sequence, *v2.RateLimitDescription, error) {
+	response, rateLimitDescription, err := Get[Sequence](ctx, c, nil, sequencesEP, sequenceID)
 	if err != nil {
 		return nil, rateLimitDescription, err
 	}
 
-	return response.Team, rateLimitDescription, nil
+	return response.Data, rateLimitDescription, nil
 }
 
-func (c *OutreachClient) ListAllProfiles(ctx context.Context, nextPageLink string) ([]*Profile, string, *v2.RateLimitDescription, error) {
-	var (
-		requestURL string
-		response   ProfilesResponse
-	)
+// UpdateSequenceOwner transfers the ownership of the sequence to the user.
+func (c *OutreachClient) UpdateSequenceOwner(ctx context.Context, sequenceID string, userID int) (*v2.RateLimitDescription, error) {
+	numericSequenceID, err := strconv.Atoi(sequenceID)
+	if err != nil {
+		return nil, err
+	}
 
-	if nextPageLink != "" {
-		requestURL = nextPageLink
-	} else {
-		rolesURL, err := url.JoinPath(baseURL, profilesEP)
-		if err != nil {
-			return nil, "", nil, err
-		}
+	requestBody := UpdateSequenceOwnerBody{
+		Id:   numericSequenceID,
+		Type: "sequence",
+	}
+	requestBody.Relationships.Owner.Data = DataDetailPair{
+		Id:   userID,
+		Type: "user",
+	}
 
-		requestURL = rolesURL
+	_, rateLimitDescription, err := Patch[Sequence](ctx, c, requestBody, sequencesEP, sequenceID)
+	if err != nil {
+		return rateLimitDescription, err
 	}
 
-	rateLimitDescription := &v2.RateLimitDescription{}
-	_, err := c.doRequest(
-		ctx,
-		http.MethodGet,
-		requestURL,
-		&response,
-		nil,
-		rateLimitDescription,
-	)
+	return rateLimitDescription, nil
+}
+
+// UpdateUserRole sets the role of the user, or removes the role of the user when roleID is nil.
+func (c *OutreachClient) UpdateUserRole(ctx context.Context, userID string, roleID *int) (*v2.RateLimitDescription, error) {
+	numericUserID, err := strconv.Atoi(userID)
 	if err != nil {
-		return nil, "", rateLimitDescription, err
+		return nil, err
+	}
+
+	requestBody := UpdateUserRoleBody{
+		Id:   numericUserID,
+		Type: "user",
+	}
+	if roleID != nil {
+		requestBody.Relationships.Role.Data = &DataDetailPair{
+			Id:   *roleID,
+			Type: "role",
+		}
 	}
 
-	var nextLink string
-	if response.Links != nil {
-		nextLink = response.Links.Next
+	_, rateLimitDescription, err := Patch[User](ctx, c, requestBody, usersEP, userID)
+	if err != nil {
+		return rateLimitDescription, err
 	}
 
-	return response.Results, nextLink, rateLimitDescription, nil
+	return rateLimitDescription, nil
 }
 
 func (c *OutreachClient) UpdateTeamMembers(ctx context.Context, teamID string, teamMembers []DataDetailPair) (*v2.RateLimitDescription, error) {
-	var requestBody struct {
-		Data UpdateTeamBody `json:"data"`
-	}
-
 	numericTeamID, err := strconv.Atoi(teamID)
 	if err != nil {
 		return nil, err
 	}
 
-	requestBody.Data = UpdateTeamBody{
+	requestBody := UpdateTeamBody{
 		Id:   numericTeamID,
 		Type: "team",
 		Relationships: UpdateTeamRelationships{
@@ -328,25 +401,77 @@ func (c *OutreachClient) UpdateTeamMembers(ctx context.Context, teamID string, t
 		},
 	}
 
-	teamURL, err := url.JoinPath(baseURL, teamsEP, teamID)
+	_, rateLimitDescription, err := Patch[Team](ctx, c, requestBody, teamsEP, teamID)
 	if err != nil {
-		return nil, err
+		return rateLimitDescription, err
 	}
 
-	rateLimitDescription := &v2.RateLimitDescription{}
-	_, err = c.doRequest(
-		ctx,
-		http.MethodPatch,
-		teamURL,
-		nil,
-		requestBody,
-		rateLimitDescription,
-	)
+	return rateLimitDescription, nil
+}
+
+// IsTeamMember reports whether the user is one of the members of the team.
+func IsTeamMember(teamMembers []DataDetailPair, userID int) bool {
+	return slices.ContainsFunc(teamMembers, func(teamMember DataDetailPair) bool {
+		return teamMember.Id == userID
+	})
+}
+
+// WithTeamMember returns the members with the user added or removed.
+func WithTeamMember(teamMembers []DataDetailPair, userID int, member bool) []DataDetailPair {
+	updatedTeamMembers := make([]DataDetailPair, 0, len(teamMembers)+1)
+	for _, teamMember := range teamMembers {
+		if teamMember.Id != userID {
+			updatedTeamMembers = append(updatedTeamMembers, teamMember)
+		}
+	}
+
+	if member {
+		updatedTeamMembers = append(updatedTeamMembers, DataDetailPair{
+			Id:   userID,
+			Type: "user",
+		})
+	}
+
+	return updatedTeamMembers
+}
+
+// pageCursor returns the cursor held by a page token. Page tokens created by previous versions of the connector
+// hold the whole link to the next page, which is only accepted when it points to the Outreach API.
+func (c *OutreachClient) pageCursor(token string) (string, error) {
+	if !strings.Contains(token, "://") {
+		return token, nil
+	}
+
+	nextURL, err := url.Parse(token)
 	if err != nil {
-		return rateLimitDescription, err
+		return "", status.Errorf(codes.InvalidArgument, "outreach: invalid page token: %s", err)
 	}
 
-	return rateLimitDescription, nil
+	err = checkOrigin(c.apiURL, nextURL)
+	if err != nil {
+		return "", err
+	}
+
+	cursor := nextURL.Query().Get(pageAfterParam)
+	if cursor == "" {
+		return "", status.Error(codes.InvalidArgument, "outreach: the page token has no cursor")
+	}
+
+	return cursor, nil
+}
+
+// endpointURL builds the URL of an Outreach API endpoint from its path segments and query.
+func (c *OutreachClient) endpointURL(query url.Values, path ...string) (string, error) {
+	endpointURL, err := url.JoinPath(c.baseURL, path...)
+	if err != nil {
+		return "", err
+	}
+
+	if len(query) > 0 {
+		endpointURL += "?" + query.Encode()
+	}
+
+	return endpointURL, nil
 }
 
 func (c *OutreachClient) doRequest(
@@ -361,7 +486,6 @@ func (c *OutreachClient) doRequest(
 		resp           *http.Response
 		responseHeader http.Header
 		err            error
-		errResponse    ErrorResponse
 	)
 
 	urlAddress, err := url.Parse(endpointUrl)
@@ -369,6 +493,17 @@ func (c *OutreachClient) doRequest(
 		return nil, err
 	}
 
+	// The bearer token is only attached to requests going to the Outreach API.
+	err = checkOrigin(c.apiURL, urlAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.rateLimiter.wait(ctx, rateLimitDescription)
+	if err != nil {
+		return nil, err
+	}
+
 	accessToken, err := c.TokenSource.Token()
 	if err != nil {
 		return nil, err
@@ -394,7 +529,7 @@ func (c *OutreachClient) doRequest(
 		return nil, err
 	}
 
-	doOptions := []uhttp.DoOption{uhttp.WithErrorResponse(&errResponse)}
+	var doOptions []uhttp.DoOption
 	if rateLimitDescription != nil {
 		doOptions = append(doOptions, uhttp.WithRatelimitData(rateLimitDescription))
 	}
@@ -402,13 +537,23 @@ func (c *OutreachClient) doRequest(
 		doOptions = append(doOptions, uhttp.WithResponse(&res))
 	}
 
-	resp, err = c.client.Do(req, doOptions...)
-	if err != nil {
-		return nil, err
+	if method == http.MethodGet && (isProvisioning(ctx) || isFreshRead(ctx)) {
+		resp, err = c.doUncached(req, doOptions...)
+	} else {
+		resp, err = c.client.Do(req, doOptions...)
 	}
 	if resp != nil && resp.Body != nil {
 		defer resp.Body.Close()
 	}
+	if resp != nil {
+		c.rateLimiter.update(resp.StatusCode, resp.Header)
+	}
+	if err != nil {
+		if resp != nil && resp.StatusCode >= http.StatusBadRequest {
+			return nil, newAPIError(resp, rateLimitDescription)
+		}
+		return nil, err
+	}
 
 	if resp != nil {
 		responseHeader = resp.Header
@@ -417,23 +562,114 @@ func (c *OutreachClient) doRequest(
 	return responseHeader, nil
 }
 
-func New(ctx context.Context, cOpts ...ConfigOption) (*OutreachClient, error) {
-	httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
+// doUncached sends the request without going through the GET cache of the SDK client, which has no way to skip it.
+// Like the SDK client, the options are applied to the response, and error statuses are returned as errors.
+func (c *OutreachClient) doUncached(req *http.Request, options ...uhttp.DoOption) (*http.Response, error) {
+	resp, err := c.client.HttpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
-	cli, err := uhttp.NewBaseHttpClientWithContext(ctx, httpClient)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	wrapperResponse := uhttp.WrapperResponse{
+		Header:     resp.Header,
+		Body:       body,
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		for _, option := range options {
+			_ = option(&wrapperResponse)
+		}
+		return resp, fmt.Errorf("outreach: request failed with status %d", resp.StatusCode)
+	}
 
+	var optionErrs []error
+	for _, option := range options {
+		if err := option(&wrapperResponse); err != nil {
+			optionErrs = append(optionErrs, err)
+		}
+	}
+
+	return resp, errors.Join(optionErrs...)
+}
+
+func New(ctx context.Context, cOpts ...ConfigOption) (*OutreachClient, error) {
 	icClient := OutreachClient{
-		client: cli,
+		rateLimiter: newRateLimiter(DefaultRateLimitReserve),
+		pageSize:    DefaultPageSize,
+		baseURL:     defaultBaseURL,
+		tokenURL:    defaultTokenURL,
+		teamLocks:   newTeamLocks(),
 	}
 	for _, option := range cOpts {
 		option(&icClient)
 	}
 
+	// The URLs set with the options are validated here, since options can't fail.
+	var err error
+	icClient.apiURL, err = ValidateURL(icClient.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	_, err = ValidateURL(icClient.tokenURL)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := icClient.httpClient
+	if httpClient == nil {
+		httpClient, err = uhttp.NewClient(ctx, uhttp.WithLogger(true, ctxzap.Extract(ctx)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	icClient.client, err = uhttp.NewBaseHttpClientWithContext(ctx, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	if icClient.refreshCredentials != nil {
+		icClient.TokenSource = icClient.newRefreshTokenSource(ctx)
+	}
+
 	return &icClient, nil
 }
+
+// refreshCredentials are the OAuth client credentials and the refresh token used to renew the access token.
+type refreshCredentials struct {
+	clientID     string
+	clientSecret string
+	refreshToken string
+}
+
+// newRefreshTokenSource returns the token source renewing the token with the refresh credentials, at the token URL
+// and with the HTTP client and the token store of the client.
+func (c *OutreachClient) newRefreshTokenSource(ctx context.Context) *storedTokenSource {
+	config := &oauth2.Config{
+		ClientID:     c.refreshCredentials.clientID,
+		ClientSecret: c.refreshCredentials.clientSecret,
+		Endpoint: oauth2.Endpoint{
+			TokenURL: c.tokenURL,
+		},
+	}
+
+	if c.httpClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, c.httpClient)
+	}
+
+	return &storedTokenSource{
+		ctx:          ctx,
+		config:       config,
+		refreshToken: c.refreshCredentials.refreshToken,
+		store:        c.tokenStore,
+	}
+}
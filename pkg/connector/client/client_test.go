@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUpdatedAtRange(t *testing.T) {
+	from := time.Date(2024, 1, 1, 10, 0, 0, 0, time.FixedZone("CET", 3600))
+	to := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		r     UpdatedAtRange
+		want  string
+		isSet bool
+	}{
+		{name: "no range"},
+		{name: "from", r: UpdatedAtRange{From: from}, want: "2024-01-01T09:00:00Z..inf", isSet: true},
+		{name: "to", r: UpdatedAtRange{To: to}, want: "neginf..2024-01-02T00:00:00Z", isSet: true},
+		{name: "from and to", r: UpdatedAtRange{From: from, To: to}, want: "2024-01-01T09:00:00Z..2024-01-02T00:00:00Z", isSet: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := url.Values{}
+			tt.r.apply(query)
+
+			if query.Has("filter[updatedAt]") != tt.isSet {
+				t.Fatalf("expected the filter to be set: %t, got %v", tt.isSet, query)
+			}
+			if got := query.Get("filter[updatedAt]"); got != tt.want {
+				t.Errorf("expected the filter %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPageCursor(t *testing.T) {
+	c, err := New(context.Background(), WithBaseURL("https://api.outreach.io/api/v2"), WithAccessToken("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		want     string
+		wantCode codes.Code
+	}{
+		{
+			name:  "cursor",
+			token: "eyJpZCI6MTB9",
+			want:  "eyJpZCI6MTB9",
+		},
+		{
+			name:  "legacy link on the same origin",
+			token: "https://api.outreach.io/api/v2/users?page%5Bafter%5D=eyJpZCI6MTB9&page%5Bsize%5D=100",
+			want:  "eyJpZCI6MTB9",
+		},
+		{
+			name:  "legacy link on the same origin with another case",
+			token: "HTTPS://API.Outreach.io/api/v2/users?page%5Bafter%5D=eyJpZCI6MTB9",
+			want:  "eyJpZCI6MTB9",
+		},
+		{
+			name:     "legacy link on a foreign host",
+			token:    "https://attacker.example.com/api/v2/users?page%5Bafter%5D=eyJpZCI6MTB9",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "legacy link on a host ending like the API one",
+			token:    "https://evilapi.outreach.io.example.com/api/v2/users?page%5Bafter%5D=eyJpZCI6MTB9",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "legacy link downgraded to HTTP",
+			token:    "http://api.outreach.io/api/v2/users?page%5Bafter%5D=eyJpZCI6MTB9",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "legacy link without cursor",
+			token:    "https://api.outreach.io/api/v2/users?page%5Bsize%5D=100",
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.pageCursor(tt.token)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("expected the code %s, got %v", tt.wantCode, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected the cursor %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestListRejectsForeignPageToken checks that a page token pointing to another host never receives the bearer token.
+func TestListRejectsForeignPageToken(t *testing.T) {
+	var foreignRequests atomic.Int32
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		foreignRequests.Add(1)
+	}))
+	defer foreign.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer api.Close()
+
+	c, err := New(context.Background(), WithBaseURL(api.URL+"/api/v2"), WithAccessToken("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _, err = c.ListAllUsers(context.Background(), foreign.URL+"/api/v2/users?page%5Bafter%5D=eyJpZCI6MTB9", UpdatedAtRange{}, false)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected the page token to be rejected, got %v", err)
+	}
+	if foreignRequests.Load() != 0 {
+		t.Error("expected no request to the foreign host")
+	}
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorResponse is the JSON:API error document Outreach returns on failed requests.
+type ErrorResponse struct {
+	Errors []ErrorObject `json:"errors"`
+}
+
+// APIError is returned for every request Outreach answers with an error status.
+// It exposes the JSON:API error objects of the response, and maps the HTTP status into a gRPC status
+// so the SDK can decide whether the request should be retried, skipped or failed.
+type APIError struct {
+	StatusCode int
+	Errors     []ErrorObject
+	RateLimit  *v2.RateLimitDescription
+}
+
+func newAPIError(resp *http.Response, rateLimitDescription *v2.RateLimitDescription) *APIError {
+	var errResponse ErrorResponse
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RateLimit:  rateLimitDescription,
+	}
+
+	if resp.Body != nil {
+		body, err := io.ReadAll(resp.Body)
+		if err == nil && json.Unmarshal(body, &errResponse) == nil {
+			apiErr.Errors = errResponse.Errors
+		}
+	}
+
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("outreach: request failed with status %d", e.StatusCode)
+	}
+
+	details := make([]string, 0, len(e.Errors))
+	for _, errObject := range e.Errors {
+		details = append(details, errObject.String())
+	}
+
+	return fmt.Sprintf("outreach: request failed with status %d: %s", e.StatusCode, strings.Join(details, "; "))
+}
+
+// Code maps the HTTP status of the response into a gRPC code.
+func (e *APIError) Code() codes.Code {
+	switch e.StatusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusTooManyRequests:
+		return codes.Unavailable
+	}
+
+	if e.StatusCode >= http.StatusInternalServerError {
+		return codes.Unavailable
+	}
+
+	return codes.Unknown
+}
+
+// GRPCStatus allows the error to be handled by the gRPC status package. Rate limit data is attached as a detail,
+// the same way the SDK does it.
+func (e *APIError) GRPCStatus() *status.Status {
+	st := status.New(e.Code(), e.Error())
+	if e.RateLimit != nil {
+		withDetails, err := st.WithDetails(e.RateLimit)
+		if err == nil {
+			return withDetails
+		}
+	}
+
+	return st
+}
+
+func (o ErrorObject) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(o.Title)
+	if o.Id != "" {
+		sb.WriteString(fmt.Sprintf(" [%s]", o.Id))
+	}
+	if o.Detail != "" {
+		sb.WriteString(": " + o.Detail)
+	}
+	if o.Source != nil && o.Source.Pointer != "" {
+		sb.WriteString(fmt.Sprintf(" (source: %s)", o.Source.Pointer))
+	}
+
+	return sb.String()
+}
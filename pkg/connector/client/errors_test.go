@@ -0,0 +1,87 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestAPIErrorCode(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		want       codes.Code
+	}{
+		{statusCode: http.StatusBadRequest, want: codes.InvalidArgument},
+		{statusCode: http.StatusUnprocessableEntity, want: codes.InvalidArgument},
+		{statusCode: http.StatusUnauthorized, want: codes.Unauthenticated},
+		{statusCode: http.StatusForbidden, want: codes.PermissionDenied},
+		{statusCode: http.StatusNotFound, want: codes.NotFound},
+		{statusCode: http.StatusConflict, want: codes.AlreadyExists},
+		{statusCode: http.StatusRequestTimeout, want: codes.DeadlineExceeded},
+		{statusCode: http.StatusTooManyRequests, want: codes.Unavailable},
+		{statusCode: http.StatusInternalServerError, want: codes.Unavailable},
+		{statusCode: http.StatusServiceUnavailable, want: codes.Unavailable},
+		{statusCode: http.StatusTeapot, want: codes.Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.statusCode), func(t *testing.T) {
+			err := &APIError{StatusCode: tt.statusCode}
+
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("expected the code %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAPIErrorFromResponse(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusUnprocessableEntity,
+		Body: io.NopCloser(strings.NewReader(`{"errors":[{"id":"validationError","title":"Validation Error",` +
+			`"detail":"Email has already been taken.","source":{"pointer":"/data/attributes/email"}}]}`)),
+	}
+
+	err := newAPIError(resp, nil)
+
+	want := "outreach: request failed with status 422: Validation Error [validationError]: Email has already been taken. (source: /data/attributes/email)"
+	if err.Error() != want {
+		t.Errorf("expected the message %q, got %q", want, err.Error())
+	}
+}
+
+func TestAPIErrorRateLimitDetails(t *testing.T) {
+	rateLimit := &v2.RateLimitDescription{
+		Status:    v2.RateLimitDescription_STATUS_OVERLIMIT,
+		Limit:     10000,
+		Remaining: 0,
+		ResetAt:   timestamppb.New(time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)),
+	}
+
+	st := status.Convert(&APIError{StatusCode: http.StatusTooManyRequests, RateLimit: rateLimit})
+	if st.Code() != codes.Unavailable {
+		t.Errorf("expected the code %s, got %s", codes.Unavailable, st.Code())
+	}
+
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("expected the rate limit as the only detail, got %v", details)
+	}
+
+	got, ok := details[0].(*v2.RateLimitDescription)
+	if !ok || !proto.Equal(got, rateLimit) {
+		t.Errorf("expected the rate limit detail %v, got %v", rateLimit, details[0])
+	}
+
+	if details := status.Convert(&APIError{StatusCode: http.StatusNotFound}).Details(); len(details) != 0 {
+		t.Errorf("expected no details without rate limit data, got %v", details)
+	}
+}
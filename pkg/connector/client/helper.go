@@ -1,9 +1,7 @@
 package client
 
 import (
-	"context"
-	"fmt"
-	"time"
+	"net/http"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
@@ -33,47 +31,81 @@ type ConfigOption func(client *OutreachClient)
 func WithTokenSource(tokenSource oauth2.TokenSource) ConfigOption {
 	return func(client *OutreachClient) {
 		client.TokenSource = tokenSource
+		client.refreshCredentials = nil
 	}
 }
 
 // WithRefreshToken receives a Refresh Token, Client ID and Client Secret from the platform to be able to renew the token when expired.
 // This ConfigOption is intended for CLI executions.
-func WithRefreshToken(ctx context.Context, clientID, clientSecret, refreshToken string) ConfigOption {
+// When a token store was set with WithTokenStore, the refresh token it holds is used instead, and every rotated token is saved to it.
+// The token source is created by New once every option is applied, so it uses the token URL and the HTTP client whatever their order.
+func WithRefreshToken(clientID, clientSecret, refreshToken string) ConfigOption {
 	return func(client *OutreachClient) {
-		token := &oauth2.Token{
-			AccessToken:  "",
-			RefreshToken: refreshToken,
-			Expiry:       time.Now().Add(-1 * time.Second),
+		client.TokenSource = nil
+		client.refreshCredentials = &refreshCredentials{
+			clientID:     clientID,
+			clientSecret: clientSecret,
+			refreshToken: refreshToken,
 		}
+	}
+}
+
+// WithTokenStore sets where the tokens refreshed by WithRefreshToken are persisted.
+func WithTokenStore(store TokenStore) ConfigOption {
+	return func(client *OutreachClient) {
+		client.tokenStore = store
+	}
+}
 
-		config := oauth2.Config{
-			ClientID:     clientID,
-			ClientSecret: clientSecret,
-			Endpoint: oauth2.Endpoint{
-				TokenURL: authURL,
-			},
+// WithBaseURL sets the base URL of the Outreach API, e.g. to use a regional host, an egress proxy or a local mock.
+// It must use HTTPS unless it points to the loopback interface. An empty URL keeps the Outreach one.
+func WithBaseURL(baseURL string) ConfigOption {
+	return func(client *OutreachClient) {
+		if baseURL != "" {
+			client.baseURL = baseURL
 		}
-		tokenSource := oauth2.ReuseTokenSource(token, config.TokenSource(ctx, token))
+	}
+}
 
-		client.TokenSource = tokenSource
+// WithTokenURL sets the URL where the refresh tokens are exchanged. It must use HTTPS unless it points
+// to the loopback interface. An empty URL keeps the Outreach one.
+func WithTokenURL(tokenURL string) ConfigOption {
+	return func(client *OutreachClient) {
+		if tokenURL != "" {
+			client.tokenURL = tokenURL
+		}
 	}
 }
 
-func WithAccessToken(accessToken string) ConfigOption {
+// WithHTTPClient sets the HTTP client used for the API requests and the token refreshes, e.g. one created
+// with NewHTTPClient to go through a proxy.
+func WithHTTPClient(httpClient *http.Client) ConfigOption {
 	return func(client *OutreachClient) {
-		client.TokenSource = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken})
+		client.httpClient = httpClient
 	}
 }
 
-type ErrorResponse struct {
-	Error       string `json:"error"`
-	Description string `json:"description"`
+// WithRateLimitReserve sets the percentage of the hourly request budget that sync requests leave for provisioning requests.
+func WithRateLimitReserve(reservePercent int) ConfigOption {
+	return func(client *OutreachClient) {
+		client.rateLimiter = newRateLimiter(reservePercent)
+	}
 }
 
-func (er *ErrorResponse) Message() string {
-	if er.Error == "" && er.Description == "" {
-		return "Error response empty"
+// WithPageSize sets the number of resources requested per page, up to the Outreach maximum.
+func WithPageSize(pageSize int) ConfigOption {
+	return func(client *OutreachClient) {
+		if pageSize <= 0 {
+			pageSize = DefaultPageSize
+		}
+
+		client.pageSize = min(pageSize, MaxPageSize)
 	}
+}
 
-	return fmt.Sprintf("API error response: %s | Error description: %s", er.Error, er.Description)
+func WithAccessToken(accessToken string) ConfigOption {
+	return func(client *OutreachClient) {
+		client.TokenSource = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken})
+		client.refreshCredentials = nil
+	}
 }
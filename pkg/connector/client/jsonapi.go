@@ -0,0 +1,266 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+)
+
+const (
+	pageSizeParam  = "page[size]"
+	pageAfterParam = "page[after]"
+	sortParam      = "sort"
+
+	// DefaultPageSize is the page size used for the collections when none is configured.
+	DefaultPageSize = 100
+	// MaxPageSize is the largest page size Outreach accepts.
+	MaxPageSize = 1000
+)
+
+// Document is a JSON:API top-level document as returned by Outreach.
+// T is the type of the primary data, e.g. *User for single resources or []*User for collections.
+type Document[T any] struct {
+	Data     T                `json:"data"`
+	Included []ResourceObject `json:"included,omitempty"`
+	Links    *Pagination      `json:"links,omitempty"`
+	Meta     *Meta            `json:"meta,omitempty"`
+	Errors   []ErrorObject    `json:"errors,omitempty"`
+}
+
+// NextCursor returns the cursor of the next page of a collection, or an empty string on the last page.
+// Only the cursor of the next link is kept, since the rest of the request is rebuilt when the next page is requested.
+// Absolute links must point to the API found at apiURL.
+func (d *Document[T]) NextCursor(apiURL *url.URL) (string, error) {
+	if d.Links == nil || d.Links.Next == "" {
+		return "", nil
+	}
+
+	nextURL, err := url.Parse(d.Links.Next)
+	if err != nil {
+		return "", err
+	}
+
+	if nextURL.IsAbs() {
+		err = checkOrigin(apiURL, nextURL)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	cursor := nextURL.Query().Get(pageAfterParam)
+	if cursor == "" {
+		return "", fmt.Errorf("outreach: the next page link has no cursor: %s", d.Links.Next)
+	}
+
+	return cursor, nil
+}
+
+// IncludedOfType decodes every included resource object of the given type.
+func IncludedOfType[T any](included []ResourceObject, resourceType string) ([]*T, error) {
+	var results []*T
+	for _, object := range included {
+		if object.Type != resourceType {
+			continue
+		}
+
+		result := new(T)
+		if err := object.Decode(result); err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// Meta holds the non-standard information Outreach adds to the documents.
+type Meta struct {
+	Count          int  `json:"count,omitempty"`
+	CountTruncated bool `json:"count_truncated,omitempty"`
+}
+
+// ResourceObject is a generic JSON:API resource object, used where the type of the resource is not known beforehand.
+type ResourceObject struct {
+	Id            int                     `json:"id"`
+	Type          string                  `json:"type"`
+	Attributes    json.RawMessage         `json:"attributes,omitempty"`
+	Relationships map[string]Relationship `json:"relationships,omitempty"`
+	Links         *ResourceLinks          `json:"links,omitempty"`
+}
+
+// Decode decodes the resource object into one of the typed resources, like User or Team.
+func (o ResourceObject) Decode(v any) error {
+	raw, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+type ResourceLinks struct {
+	Self string `json:"self,omitempty"`
+}
+
+// Relationship is a JSON:API relationship object. Data holds either a single resource identifier, an array of them or null.
+type Relationship struct {
+	Data  json.RawMessage    `json:"data,omitempty"`
+	Links *RelationshipLinks `json:"links,omitempty"`
+	Meta  *Meta              `json:"meta,omitempty"`
+}
+
+type RelationshipLinks struct {
+	Self    string `json:"self,omitempty"`
+	Related string `json:"related,omitempty"`
+}
+
+// One returns the resource identifier of a to-one relationship, or nil when the relationship is empty.
+func (r Relationship) One() (*DataDetailPair, error) {
+	var identifier *DataDetailPair
+	if len(r.Data) == 0 {
+		return nil, nil
+	}
+
+	if err := json.Unmarshal(r.Data, &identifier); err != nil {
+		return nil, err
+	}
+
+	return identifier, nil
+}
+
+// Many returns the resource identifiers of a to-many relationship.
+func (r Relationship) Many() ([]DataDetailPair, error) {
+	var identifiers []DataDetailPair
+	if len(r.Data) == 0 {
+		return nil, nil
+	}
+
+	if err := json.Unmarshal(r.Data, &identifiers); err != nil {
+		return nil, err
+	}
+
+	return identifiers, nil
+}
+
+// ErrorObject is a JSON:API error object.
+type ErrorObject struct {
+	Id     string       `json:"id"`
+	Title  string       `json:"title"`
+	Detail string       `json:"detail"`
+	Source *ErrorSource `json:"source,omitempty"`
+}
+
+type ErrorSource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+}
+
+// requestDocument wraps the primary data of the documents sent to Outreach.
+type requestDocument struct {
+	Data any `json:"data"`
+}
+
+// List requests a page of the collection found under the given path. Pages are requested with a stable sort,
+// and the cursor returned with a page gives the next one. An empty cursor requests the first page.
+func List[T any](
+	ctx context.Context,
+	c *OutreachClient,
+	cursor string,
+	query url.Values,
+	path ...string,
+) (*Document[[]*T], string, *v2.RateLimitDescription, error) {
+	var response Document[[]*T]
+
+	cursor, err := c.pageCursor(cursor)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	pageQuery := url.Values{}
+	for key, values := range query {
+		pageQuery[key] = values
+	}
+	pageQuery.Set(pageSizeParam, strconv.Itoa(c.pageSize))
+	pageQuery.Set(sortParam, "id")
+	if cursor != "" {
+		pageQuery.Set(pageAfterParam, cursor)
+	}
+
+	requestURL, err := c.endpointURL(pageQuery, path...)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	rateLimitDescription := &v2.RateLimitDescription{}
+	_, err = c.doRequest(
+		ctx,
+		http.MethodGet,
+		requestURL,
+		&response,
+		nil,
+		rateLimitDescription,
+	)
+	if err != nil {
+		return nil, "", rateLimitDescription, err
+	}
+
+	nextCursor, err := response.NextCursor(c.apiURL)
+	if err != nil {
+		return nil, "", rateLimitDescription, err
+	}
+
+	return &response, nextCursor, rateLimitDescription, nil
+}
+
+// Get requests a single resource found under the given path.
+func Get[T any](ctx context.Context, c *OutreachClient, query url.Values, path ...string) (*Document[*T], *v2.RateLimitDescription, error) {
+	return sendDocument[T](ctx, c, http.MethodGet, query, nil, path...)
+}
+
+// Patch updates the resource found under the given path. The data is sent as the primary data of the request document.
+func Patch[T any](ctx context.Context, c *OutreachClient, data any, path ...string) (*Document[*T], *v2.RateLimitDescription, error) {
+	return sendDocument[T](ctx, c, http.MethodPatch, nil, requestDocument{Data: data}, path...)
+}
+
+// Create creates a new resource on the collection found under the given path.
+// The data is sent as the primary data of the request document.
+func Create[T any](ctx context.Context, c *OutreachClient, data any, path ...string) (*Document[*T], *v2.RateLimitDescription, error) {
+	return sendDocument[T](ctx, c, http.MethodPost, nil, requestDocument{Data: data}, path...)
+}
+
+func sendDocument[T any](
+	ctx context.Context,
+	c *OutreachClient,
+	method string,
+	query url.Values,
+	body any,
+	path ...string,
+) (*Document[*T], *v2.RateLimitDescription, error) {
+	var response Document[*T]
+
+	requestURL, err := c.endpointURL(query, path...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rateLimitDescription := &v2.RateLimitDescription{}
+	_, err = c.doRequest(
+		ctx,
+		method,
+		requestURL,
+		&response,
+		body,
+		rateLimitDescription,
+	)
+	if err != nil {
+		return nil, rateLimitDescription, err
+	}
+
+	return &response, rateLimitDescription, nil
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	loginCallbackPath = "/callback"
+	loginStateLength  = 32
+)
+
+// LoginConfig configures the authorization code flow used to get a refresh token for the connector.
+type LoginConfig struct {
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// RedirectPort is the loopback port receiving the authorization code. It must match the redirect URI
+	// registered on the Outreach application. Zero picks a free port.
+	RedirectPort int
+	// OpenURL sends the user to the authorization URL, usually by opening a browser or printing it.
+	OpenURL func(authorizationURL string) error
+	// TokenURL overrides the Outreach token URL when set. The authorization URL is found next to it.
+	TokenURL string
+}
+
+// Login runs the OAuth authorization code flow. A callback server listens on the loopback interface for the redirect
+// of the authorization, and the code it receives is exchanged for the tokens. Callbacks with another state are
+// refused, and the login waits for the authorization until the context is done.
+func Login(ctx context.Context, config LoginConfig) (*oauth2.Token, error) {
+	if config.ClientID == "" || config.ClientSecret == "" {
+		return nil, fmt.Errorf("outreach: the client ID and the client secret are required to log in")
+	}
+
+	endpoint, err := OAuthEndpoint(config.TokenURL)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", config.RedirectPort))
+	if err != nil {
+		return nil, fmt.Errorf("outreach: unable to listen for the authorization callback: %w", err)
+	}
+	defer listener.Close()
+
+	oauthConfig := &oauth2.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		Endpoint:     endpoint,
+		RedirectURL: (&url.URL{
+			Scheme: "http",
+			Host:   listener.Addr().String(),
+			Path:   loginCallbackPath,
+		}).String(),
+		Scopes: config.Scopes,
+	}
+
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	codes := make(chan string, 1)
+	callbackErrors := make(chan error, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(loginCallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		// A callback without the state of this login doesn't answer its authorization, like a stale tab or
+		// a forged request, so it is refused and the login keeps waiting for the right one.
+		if r.URL.Query().Get("state") != state {
+			http.Error(w, "outreach: the authorization callback has an unexpected state", http.StatusBadRequest)
+			return
+		}
+
+		code, err := authorizationCode(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			select {
+			case callbackErrors <- err:
+			default:
+			}
+			return
+		}
+
+		_, _ = fmt.Fprintln(w, "Outreach authorization received, you can close this window.")
+		select {
+		case codes <- code:
+		default:
+		}
+	})
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Close()
+
+	err = config.OpenURL(oauthConfig.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier)))
+	if err != nil {
+		return nil, err
+	}
+
+	var code string
+	select {
+	case code = <-codes:
+	case err = <-callbackErrors:
+		return nil, err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("outreach: the authorization wasn't received: %w", ctx.Err())
+	}
+
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("outreach: unable to exchange the authorization code: %w", err)
+	}
+
+	if token.RefreshToken == "" {
+		return nil, errors.New("outreach: no refresh token was issued with the authorization")
+	}
+
+	return token, nil
+}
+
+// authorizationCode returns the code of the authorization redirect, or the reason the authorization was denied.
+func authorizationCode(query url.Values) (string, error) {
+	if authErr := query.Get("error"); authErr != "" {
+		return "", fmt.Errorf("outreach: the authorization was denied: %s %s", authErr, query.Get("error_description"))
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return "", errors.New("outreach: the authorization callback has no code")
+	}
+
+	return code, nil
+}
+
+func randomState() (string, error) {
+	state := make([]byte, loginStateLength)
+	_, err := rand.Read(state)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(state), nil
+}
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// fakeOAuthServer stands in for the Outreach authorization server. The authorize endpoint approves the request
+// right away, redirecting back with a code, and the token endpoint exchanges that code.
+func fakeOAuthServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var (
+		challenge   string
+		challengeMu sync.Mutex
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("client_id") != "client-id" || query.Get("response_type") != "code" {
+			http.Error(w, "unexpected authorization request", http.StatusBadRequest)
+			return
+		}
+		if query.Get("scope") != "users.read teams.write" {
+			http.Error(w, "unexpected scopes: "+query.Get("scope"), http.StatusBadRequest)
+			return
+		}
+		challengeMu.Lock()
+		challenge = query.Get("code_challenge")
+		challengeMu.Unlock()
+
+		redirectURL, err := url.Parse(query.Get("redirect_uri"))
+		if err != nil || redirectURL.Hostname() != "127.0.0.1" {
+			http.Error(w, "unexpected redirect URI", http.StatusBadRequest)
+			return
+		}
+
+		redirectQuery := url.Values{}
+		redirectQuery.Set("code", "authorization-code")
+		redirectQuery.Set("state", query.Get("state"))
+		redirectURL.RawQuery = redirectQuery.Encode()
+		http.Redirect(w, r, redirectURL.String(), http.StatusFound)
+	})
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("code") != "authorization-code" {
+			http.Error(w, "unexpected token request", http.StatusBadRequest)
+			return
+		}
+		challengeMu.Lock()
+		expectedChallenge := challenge
+		challengeMu.Unlock()
+		if expectedChallenge == "" || oauth2.S256ChallengeFromVerifier(r.PostForm.Get("code_verifier")) != expectedChallenge {
+			http.Error(w, "unexpected code verifier", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  "access-token",
+			"refresh_token": "refresh-token",
+			"token_type":    "bearer",
+			"expires_in":    7200,
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func testLoginConfig(server *httptest.Server) LoginConfig {
+	return LoginConfig{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		Scopes:       []string{"users.read", "teams.write"},
+		TokenURL:     server.URL + "/oauth/token",
+		// The browser of the user is replaced by a client following the redirects.
+		OpenURL: func(authorizationURL string) error {
+			go func() {
+				resp, err := http.Get(authorizationURL)
+				if err == nil {
+					resp.Body.Close()
+				}
+			}()
+			return nil
+		},
+	}
+}
+
+func TestLogin(t *testing.T) {
+	server := fakeOAuthServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token, err := Login(ctx, testLoginConfig(server))
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+
+	if token.AccessToken != "access-token" || token.RefreshToken != "refresh-token" {
+		t.Errorf("unexpected token: %+v", token)
+	}
+}
+
+func TestLoginRejectsUnexpectedState(t *testing.T) {
+	tests := []struct {
+		name string
+		// authorize follows the authorization after the callback with an unexpected state.
+		authorize bool
+		wantErr   string
+	}{
+		{
+			name:      "authorization received afterwards",
+			authorize: true,
+		},
+		{
+			name:    "authorization never received",
+			wantErr: "the authorization wasn't received",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fakeOAuthServer(t)
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			forgedStatus := make(chan int, 1)
+			config := testLoginConfig(server)
+			openURL := config.OpenURL
+			config.OpenURL = func(authorizationURL string) error {
+				parsed, err := url.Parse(authorizationURL)
+				if err != nil {
+					return err
+				}
+
+				callbackURL, err := url.Parse(parsed.Query().Get("redirect_uri"))
+				if err != nil {
+					return err
+				}
+				callbackURL.RawQuery = url.Values{"state": {"forged-state"}, "code": {"forged-code"}}.Encode()
+
+				resp, err := http.Get(callbackURL.String())
+				if err != nil {
+					return err
+				}
+				resp.Body.Close()
+				forgedStatus <- resp.StatusCode
+
+				if !tt.authorize {
+					return nil
+				}
+				return openURL(authorizationURL)
+			}
+
+			token, err := Login(ctx, config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected an error containing %q, got: %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("login failed: %v", err)
+			} else if token.RefreshToken != "refresh-token" {
+				t.Errorf("unexpected token: %+v", token)
+			}
+
+			if status := <-forgedStatus; status != http.StatusBadRequest {
+				t.Errorf("expected the callback with an unexpected state to be refused, got the status %d", status)
+			}
+		})
+	}
+}
+
+func TestLoginSavesToTokenStore(t *testing.T) {
+	server := fakeOAuthServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token, err := Login(ctx, testLoginConfig(server))
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+
+	store := NewFileTokenStore(t.TempDir()+"/token.json", "passphrase")
+	if err = store.Save(token); err != nil {
+		t.Fatalf("unable to save the token: %v", err)
+	}
+
+	stored, err := store.Load()
+	if err != nil {
+		t.Fatalf("unable to load the token: %v", err)
+	}
+	if stored.RefreshToken != "refresh-token" {
+		t.Errorf("unexpected stored refresh token: %s", stored.RefreshToken)
+	}
+
+	_, err = NewFileTokenStore(store.path, "wrong passphrase").Load()
+	if err == nil {
+		t.Errorf("expected the token store to be unreadable with another key")
+	}
+}
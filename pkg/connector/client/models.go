@@ -85,7 +85,15 @@ type UserRelationships struct {
 	} `json:"profile,omitempty"`
 	Teams *struct {
 		Data *[]DataDetailPair `json:"data,omitempty"`
+		// Meta counts all the teams, since Outreach caps the ones embedded in Data.
+		Meta *Meta `json:"meta,omitempty"`
 	} `json:"teams,omitempty"`
+	Role *struct {
+		Data *DataDetailPair `json:"data,omitempty"`
+	} `json:"role,omitempty"`
+	Manager *struct {
+		Data *DataDetailPair `json:"data,omitempty"`
+	} `json:"manager,omitempty"`
 }
 
 type User struct {
@@ -93,11 +101,17 @@ type User struct {
 	Attributes    UserAttributes     `json:"attributes"`
 	Relationships *UserRelationships `json:"relationships,omitempty"`
 	Type          string             `json:"type"`
+	// Manager is the manager of the user, resolved by the list requests from the listed and included users.
+	Manager *User `json:"-"`
 }
 
-type UsersResponse struct {
-	Links   *Pagination `json:"links,omitempty"`
-	Results []*User     `json:"data"`
+// ManagerID returns the ID of the manager of the user, or 0 when it has none.
+func (u *User) ManagerID() int {
+	if u.Relationships == nil || u.Relationships.Manager == nil || u.Relationships.Manager.Data == nil {
+		return 0
+	}
+
+	return u.Relationships.Manager.Data.Id
 }
 
 type ProfileAttributes struct {
@@ -114,11 +128,6 @@ type Profile struct {
 	Type       string            `json:"type"`
 }
 
-type ProfilesResponse struct {
-	Links   *Pagination `json:"links,omitempty"`
-	Results []*Profile  `json:"data"`
-}
-
 type TeamAttributes struct {
 	Color          string `json:"color"`
 	CreatedAt      string `json:"createdAt"`
@@ -141,11 +150,6 @@ type Team struct {
 	Type          string             `json:"type"`
 }
 
-type TeamsResponse struct {
-	Links   *Pagination `json:"links,omitempty"`
-	Results []*Team     `json:"data"`
-}
-
 type UpdateTeamBody struct {
 	Id            int                     `json:"id"`
 	Type          string                  `json:"type"` // Type should always be 'team'.
@@ -175,6 +179,48 @@ type UserProfileRelationships struct {
 	} `json:"profile"`
 }
 
+// UpdateUserRoleBody sets the role of a user, or removes it when the role is nil.
+type UpdateUserRoleBody struct {
+	Id            int                   `json:"id"`
+	Type          string                `json:"type"`
+	Relationships UserRoleRelationships `json:"relationships"`
+}
+
+type UserRoleRelationships struct {
+	Role struct {
+		Data *DataDetailPair `json:"data"`
+	} `json:"role"`
+}
+
+type RoleAttributes struct {
+	CreatedAt string `json:"createdAt"`
+	Name      string `json:"name"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type RoleRelationships struct {
+	ParentRole *struct {
+		Data *DataDetailPair `json:"data,omitempty"`
+	} `json:"parentRole,omitempty"`
+}
+
+// Role is a role of the organization hierarchy, used for reporting and data visibility.
+type Role struct {
+	Attributes    RoleAttributes     `json:"attributes"`
+	Id            int                `json:"id"`
+	Relationships *RoleRelationships `json:"relationships,omitempty"`
+	Type          string             `json:"type"`
+}
+
+// ParentRoleID returns the ID of the parent role, or 0 for the roles at the top of the hierarchy.
+func (r *Role) ParentRoleID() int {
+	if r.Relationships == nil || r.Relationships.ParentRole == nil || r.Relationships.ParentRole.Data == nil {
+		return 0
+	}
+
+	return r.Relationships.ParentRole.Data.Id
+}
+
 type NewUserBody struct {
 	Data struct {
 		Type       string            `json:"type"` // The type should always be 'user'.
@@ -195,3 +241,98 @@ type UserLockStatusUpdate struct {
 		Locked bool `json:"locked"`
 	} `json:"attributes"`
 }
+
+type MailboxAttributes struct {
+	CreatedAt      string `json:"createdAt"`
+	Email          string `json:"email"`
+	ProviderType   string `json:"providerType"`
+	SendDisabled   bool   `json:"sendDisabled"`
+	SyncDisabled   bool   `json:"syncDisabled"`
+	SyncFinishedAt string `json:"syncFinishedAt"`
+	UpdatedAt      string `json:"updatedAt"`
+}
+
+type MailboxRelationships struct {
+	User *struct {
+		Data *DataDetailPair `json:"data,omitempty"`
+	} `json:"user,omitempty"`
+}
+
+// Mailbox is an email account connected to Outreach, which the sequences of its user send from.
+type Mailbox struct {
+	Attributes    MailboxAttributes     `json:"attributes"`
+	Id            int                   `json:"id"`
+	Relationships *MailboxRelationships `json:"relationships,omitempty"`
+	Type          string                `json:"type"`
+}
+
+// OwnerID returns the ID of the user owning the mailbox, or 0 when it has none.
+func (m *Mailbox) OwnerID() int {
+	if m.Relationships == nil || m.Relationships.User == nil || m.Relationships.User.Data == nil {
+		return 0
+	}
+
+	return m.Relationships.User.Data.Id
+}
+
+type SequenceAttributes struct {
+	CreatedAt string `json:"createdAt"`
+	Enabled   bool   `json:"enabled"`
+	Name      string `json:"name"`
+	// ShareType is either 'private', 'read_only' or 'shared'.
+	ShareType string `json:"shareType"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type SequenceRelationships struct {
+	Owner *struct {
+		Data *DataDetailPair `json:"data,omitempty"`
+	} `json:"owner,omitempty"`
+	// Teams are the teams the sequence is shared with.
+	Teams *struct {
+		Data []DataDetailPair `json:"data,omitempty"`
+	} `json:"teams,omitempty"`
+}
+
+type Sequence struct {
+	Attributes    SequenceAttributes     `json:"attributes"`
+	Id            int                    `json:"id"`
+	Relationships *SequenceRelationships `json:"relationships,omitempty"`
+	Type          string                 `json:"type"`
+}
+
+// OwnerID returns the ID of the user owning the sequence, or 0 when it has none.
+func (s *Sequence) OwnerID() int {
+	if s.Relationships == nil || s.Relationships.Owner == nil || s.Relationships.Owner.Data == nil {
+		return 0
+	}
+
+	return s.Relationships.Owner.Data.Id
+}
+
+// TeamIDs returns the IDs of the teams the sequence is shared with.
+func (s *Sequence) TeamIDs() []int {
+	if s.Relationships == nil || s.Relationships.Teams == nil {
+		return nil
+	}
+
+	teamIDs := make([]int, 0, len(s.Relationships.Teams.Data))
+	for _, team := range s.Relationships.Teams.Data {
+		teamIDs = append(teamIDs, team.Id)
+	}
+
+	return teamIDs
+}
+
+// UpdateSequenceOwnerBody transfers the ownership of a sequence to another user.
+type UpdateSequenceOwnerBody struct {
+	Id            int                        `json:"id"`
+	Type          string                     `json:"type"`
+	Relationships SequenceOwnerRelationships `json:"relationships"`
+}
+
+type SequenceOwnerRelationships struct {
+	Owner struct {
+		Data DataDetailPair `json:"data"`
+	} `json:"owner"`
+}
@@ -0,0 +1,248 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// DefaultRateLimitReserve is the percentage of the hourly request budget kept for provisioning requests by default.
+	DefaultRateLimitReserve = 10
+
+	rateLimitLimitHeader     = "X-RateLimit-Limit"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	rateLimitResetHeader     = "X-RateLimit-Reset"
+	retryAfterHeader         = "Retry-After"
+
+	// Below this fraction of the available budget the requests start being spread until the budget resets.
+	rateLimitPacingThreshold = 0.5
+	// Values of the reset header above this one are unix timestamps instead of seconds.
+	unixTimestampThreshold = 1_000_000_000
+	// maxRateLimitWait is the longest a request is held back. Requests which would have to wait longer fail as unavailable
+	// with the time the budget resets, so the SDK retries them then instead of blocking the sync.
+	maxRateLimitWait = 5 * time.Second
+)
+
+type provisioningContextKey struct{}
+
+// ContextWithProvisioning marks the requests made with the returned context as provisioning requests.
+// Those requests are allowed to use the part of the budget the rate limiter keeps in reserve, and their reads
+// skip the response cache, so the changes they make are seen by the following reads.
+func ContextWithProvisioning(ctx context.Context) context.Context {
+	return context.WithValue(ctx, provisioningContextKey{}, true)
+}
+
+type freshReadContextKey struct{}
+
+// ContextWithFreshRead makes the reads done with the returned context skip the response cache, without using the
+// reserve of provisioning requests. It is meant for the reads deciding whether the data of a previous sync is still valid.
+func ContextWithFreshRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshReadContextKey{}, true)
+}
+
+func isFreshRead(ctx context.Context) bool {
+	fresh, ok := ctx.Value(freshReadContextKey{}).(bool)
+	return ok && fresh
+}
+
+// Throttled reports whether the requests to Outreach are currently being spread or held back by the rate limiter.
+// Optional work, like prefetching, should be left for later while it is.
+func (c *OutreachClient) Throttled() bool {
+	return c.rateLimiter.throttled()
+}
+
+func isProvisioning(ctx context.Context) bool {
+	provisioning, ok := ctx.Value(provisioningContextKey{}).(bool)
+	return ok && provisioning
+}
+
+// rateLimiter paces the requests to Outreach using the rate limit headers of its responses.
+// Outreach enforces an hourly budget per user, so once the remaining budget drops the requests are spread
+// until the budget resets, and a percentage of it is kept for provisioning requests sharing the same token.
+type rateLimiter struct {
+	mu sync.Mutex
+
+	reserve   float64
+	limit     int64
+	remaining int64
+	resetAt   time.Time
+	retryAt   time.Time
+
+	now func() time.Time
+}
+
+func newRateLimiter(reservePercent int) *rateLimiter {
+	reservePercent = min(max(reservePercent, 0), 100)
+
+	return &rateLimiter{
+		reserve: float64(reservePercent) / 100,
+		now:     time.Now,
+	}
+}
+
+// wait blocks until the request can be made without exhausting the budget, or the context is done.
+// When the budget only frees up later than maxRateLimitWait, it returns an unavailable error carrying the rate
+// limit description instead, and fills rateLimitDescription with it when given.
+func (r *rateLimiter) wait(ctx context.Context, rateLimitDescription *v2.RateLimitDescription) error {
+	delay, overLimit := r.reserveRequest(isProvisioning(ctx))
+	if overLimit != nil {
+		if rateLimitDescription != nil {
+			rateLimitDescription.Status = overLimit.Status
+			rateLimitDescription.Limit = overLimit.Limit
+			rateLimitDescription.Remaining = overLimit.Remaining
+			rateLimitDescription.ResetAt = overLimit.ResetAt
+		}
+
+		st, err := status.New(codes.Unavailable, "outreach: rate limit exceeded").WithDetails(overLimit)
+		if err != nil {
+			return status.Error(codes.Unavailable, "outreach: rate limit exceeded")
+		}
+		return st.Err()
+	}
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserveRequest takes a request from the known budget and returns how long the request should wait, at most
+// maxRateLimitWait. When the request can't be made within that time, nothing is taken and the rate limit description
+// of the exhausted budget is returned instead.
+func (r *rateLimiter) reserveRequest(provisioning bool) (time.Duration, *v2.RateLimitDescription) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if now.Before(r.retryAt) {
+		return r.holdUntil(now, r.retryAt)
+	}
+
+	// Nothing is known about the budget until the first response, or the budget was already reset.
+	if r.limit <= 0 || !now.Before(r.resetAt) {
+		return 0, nil
+	}
+
+	available := r.remaining
+	if !provisioning {
+		available -= int64(float64(r.limit) * r.reserve)
+	}
+
+	if available <= 0 {
+		return r.holdUntil(now, r.resetAt)
+	}
+
+	r.remaining--
+
+	usable := float64(r.limit) * (1 - r.reserve)
+	if provisioning || float64(available) > usable*rateLimitPacingThreshold {
+		return 0, nil
+	}
+
+	return min(r.resetAt.Sub(now)/time.Duration(available), maxRateLimitWait), nil
+}
+
+// holdUntil returns the delay until the given time when it is short enough to wait, or the rate limit description
+// telling when to retry otherwise.
+func (r *rateLimiter) holdUntil(now, until time.Time) (time.Duration, *v2.RateLimitDescription) {
+	delay := until.Sub(now)
+	if delay <= maxRateLimitWait {
+		return delay, nil
+	}
+
+	return 0, &v2.RateLimitDescription{
+		Status:    v2.RateLimitDescription_STATUS_OVERLIMIT,
+		Limit:     r.limit,
+		Remaining: r.remaining,
+		ResetAt:   timestamppb.New(until),
+	}
+}
+
+// throttled reports whether the requests are being spread or held back, because the remaining budget is low or
+// Outreach asked to retry later.
+func (r *rateLimiter) throttled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if now.Before(r.retryAt) {
+		return true
+	}
+
+	if r.limit <= 0 || !now.Before(r.resetAt) {
+		return false
+	}
+
+	available := r.remaining - int64(float64(r.limit)*r.reserve)
+	usable := float64(r.limit) * (1 - r.reserve)
+
+	return float64(available) <= usable*rateLimitPacingThreshold
+}
+
+// update refreshes the known budget with the headers of a response.
+func (r *rateLimiter) update(statusCode int, header http.Header) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+
+	if limit, err := strconv.ParseInt(header.Get(rateLimitLimitHeader), 10, 64); err == nil {
+		r.limit = limit
+	}
+
+	if remaining, err := strconv.ParseInt(header.Get(rateLimitRemainingHeader), 10, 64); err == nil {
+		r.remaining = remaining
+	}
+
+	if reset, err := strconv.ParseInt(header.Get(rateLimitResetHeader), 10, 64); err == nil {
+		r.resetAt = parseResetTime(now, reset)
+	}
+
+	if retryAfter := parseRetryAfter(now, header.Get(retryAfterHeader)); !retryAfter.IsZero() {
+		r.retryAt = retryAfter
+	} else if statusCode == http.StatusTooManyRequests {
+		r.remaining = 0
+	}
+}
+
+func parseResetTime(now time.Time, reset int64) time.Time {
+	if reset > unixTimestampThreshold {
+		return time.Unix(reset, 0)
+	}
+
+	return now.Add(time.Duration(reset) * time.Second)
+}
+
+// parseRetryAfter parses the Retry-After header, which holds either a number of seconds or an HTTP date.
+func parseRetryAfter(now time.Time, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return date
+	}
+
+	return time.Time{}
+}
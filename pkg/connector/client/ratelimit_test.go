@@ -0,0 +1,290 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestRateLimiter(now time.Time) *rateLimiter {
+	r := newRateLimiter(DefaultRateLimitReserve)
+	r.now = func() time.Time { return now }
+
+	return r
+}
+
+func TestRateLimiterReserveRequest(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		limit         int64
+		remaining     int64
+		resetAt       time.Time
+		retryAt       time.Time
+		provisioning  bool
+		wantDelay     time.Duration
+		wantRemaining int64
+		// wantResetAt is when the request can be retried, when it can't be made within maxRateLimitWait.
+		wantResetAt time.Time
+	}{
+		{
+			name:      "budget unknown",
+			wantDelay: 0,
+		},
+		{
+			name:          "plenty of budget",
+			limit:         1000,
+			remaining:     1000,
+			resetAt:       now.Add(time.Hour),
+			wantRemaining: 999,
+		},
+		{
+			name:          "budget below the pacing threshold",
+			limit:         1000,
+			remaining:     400,
+			resetAt:       now.Add(time.Hour),
+			wantDelay:     maxRateLimitWait,
+			wantRemaining: 399,
+		},
+		{
+			name:          "budget spread below the maximum wait",
+			limit:         1000,
+			remaining:     400,
+			resetAt:       now.Add(10 * time.Minute),
+			wantDelay:     10 * time.Minute / 300,
+			wantRemaining: 399,
+		},
+		{
+			name:          "only the reserve left",
+			limit:         1000,
+			remaining:     100,
+			resetAt:       now.Add(time.Hour),
+			wantRemaining: 100,
+			wantResetAt:   now.Add(time.Hour),
+		},
+		{
+			name:          "only the reserve left until a close reset",
+			limit:         1000,
+			remaining:     100,
+			resetAt:       now.Add(3 * time.Second),
+			wantDelay:     3 * time.Second,
+			wantRemaining: 100,
+		},
+		{
+			name:          "only the reserve left for provisioning",
+			limit:         1000,
+			remaining:     100,
+			resetAt:       now.Add(time.Hour),
+			provisioning:  true,
+			wantRemaining: 99,
+		},
+		{
+			name:          "provisioning below the pacing threshold",
+			limit:         1000,
+			remaining:     400,
+			resetAt:       now.Add(time.Hour),
+			provisioning:  true,
+			wantRemaining: 399,
+		},
+		{
+			name:          "budget already reset",
+			limit:         1000,
+			remaining:     0,
+			resetAt:       now.Add(-time.Minute),
+			wantRemaining: 0,
+		},
+		{
+			name:          "retry after",
+			limit:         1000,
+			remaining:     1000,
+			resetAt:       now.Add(time.Hour),
+			retryAt:       now.Add(30 * time.Second),
+			provisioning:  true,
+			wantRemaining: 1000,
+			wantResetAt:   now.Add(30 * time.Second),
+		},
+		{
+			name:          "retry after a few seconds",
+			limit:         1000,
+			remaining:     1000,
+			resetAt:       now.Add(time.Hour),
+			retryAt:       now.Add(2 * time.Second),
+			wantDelay:     2 * time.Second,
+			wantRemaining: 1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRateLimiter(now)
+			r.limit = tt.limit
+			r.remaining = tt.remaining
+			r.resetAt = tt.resetAt
+			r.retryAt = tt.retryAt
+
+			delay, overLimit := r.reserveRequest(tt.provisioning)
+			if delay != tt.wantDelay {
+				t.Errorf("expected a delay of %s, got %s", tt.wantDelay, delay)
+			}
+			switch {
+			case tt.wantResetAt.IsZero() && overLimit != nil:
+				t.Errorf("expected the request to be allowed, got over the limit until %s", overLimit.ResetAt.AsTime())
+			case !tt.wantResetAt.IsZero() && overLimit == nil:
+				t.Errorf("expected the request to be over the limit until %s", tt.wantResetAt)
+			case overLimit != nil && !overLimit.ResetAt.AsTime().Equal(tt.wantResetAt):
+				t.Errorf("expected the limit to reset at %s, got %s", tt.wantResetAt, overLimit.ResetAt.AsTime())
+			}
+			if r.remaining != tt.wantRemaining {
+				t.Errorf("expected %d remaining requests, got %d", tt.wantRemaining, r.remaining)
+			}
+		})
+	}
+}
+
+func TestRateLimiterWaitOverLimit(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	r := newTestRateLimiter(now)
+	r.limit = 1000
+	r.remaining = 50
+	r.resetAt = now.Add(time.Hour)
+
+	rateLimitData := &v2.RateLimitDescription{}
+	err := r.wait(context.Background(), rateLimitData)
+
+	st, _ := status.FromError(err)
+	if st.Code() != codes.Unavailable {
+		t.Fatalf("expected an unavailable error, got %v", err)
+	}
+
+	var details *v2.RateLimitDescription
+	for _, detail := range st.Details() {
+		if rateLimit, ok := detail.(*v2.RateLimitDescription); ok {
+			details = rateLimit
+		}
+	}
+	if details == nil || !details.ResetAt.AsTime().Equal(r.resetAt) || details.Status != v2.RateLimitDescription_STATUS_OVERLIMIT {
+		t.Errorf("expected the error to carry the reset of the budget at %s, got %v", r.resetAt, details)
+	}
+	if !rateLimitData.ResetAt.AsTime().Equal(r.resetAt) || rateLimitData.Remaining != 50 {
+		t.Errorf("expected the rate limit data to be filled with the exhausted budget, got %v", rateLimitData)
+	}
+}
+
+func TestRateLimiterUpdate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		statusCode    int
+		header        map[string]string
+		wantLimit     int64
+		wantRemaining int64
+		wantResetAt   time.Time
+		wantRetryAt   time.Time
+		wantThrottled bool
+	}{
+		{
+			name:       "reset in seconds",
+			statusCode: http.StatusOK,
+			header: map[string]string{
+				rateLimitLimitHeader:     "1000",
+				rateLimitRemainingHeader: "900",
+				rateLimitResetHeader:     "600",
+			},
+			wantLimit:     1000,
+			wantRemaining: 900,
+			wantResetAt:   now.Add(10 * time.Minute),
+		},
+		{
+			name:       "reset as a unix timestamp",
+			statusCode: http.StatusOK,
+			header: map[string]string{
+				rateLimitLimitHeader:     "1000",
+				rateLimitRemainingHeader: "300",
+				rateLimitResetHeader:     "1704114000",
+			},
+			wantLimit:     1000,
+			wantRemaining: 300,
+			wantResetAt:   time.Unix(1704114000, 0),
+			wantThrottled: true,
+		},
+		{
+			name:       "retry after seconds",
+			statusCode: http.StatusTooManyRequests,
+			header: map[string]string{
+				rateLimitLimitHeader:     "1000",
+				rateLimitRemainingHeader: "500",
+				rateLimitResetHeader:     "600",
+				retryAfterHeader:         "30",
+			},
+			wantLimit:     1000,
+			wantRemaining: 500,
+			wantResetAt:   now.Add(10 * time.Minute),
+			wantRetryAt:   now.Add(30 * time.Second),
+			wantThrottled: true,
+		},
+		{
+			name:       "retry after an HTTP date",
+			statusCode: http.StatusTooManyRequests,
+			header: map[string]string{
+				retryAfterHeader: now.Add(2 * time.Minute).Format(http.TimeFormat),
+			},
+			wantRetryAt:   now.Add(2 * time.Minute),
+			wantThrottled: true,
+		},
+		{
+			name:       "too many requests without retry after",
+			statusCode: http.StatusTooManyRequests,
+			header: map[string]string{
+				rateLimitLimitHeader:     "1000",
+				rateLimitRemainingHeader: "500",
+				rateLimitResetHeader:     "600",
+			},
+			wantLimit:     1000,
+			wantRemaining: 0,
+			wantResetAt:   now.Add(10 * time.Minute),
+			wantThrottled: true,
+		},
+		{
+			name:       "invalid retry after",
+			statusCode: http.StatusOK,
+			header: map[string]string{
+				retryAfterHeader: "soon",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRateLimiter(now)
+
+			header := http.Header{}
+			for key, value := range tt.header {
+				header.Set(key, value)
+			}
+			r.update(tt.statusCode, header)
+
+			if r.limit != tt.wantLimit {
+				t.Errorf("expected the limit %d, got %d", tt.wantLimit, r.limit)
+			}
+			if r.remaining != tt.wantRemaining {
+				t.Errorf("expected %d remaining requests, got %d", tt.wantRemaining, r.remaining)
+			}
+			if !r.resetAt.Equal(tt.wantResetAt) {
+				t.Errorf("expected the budget to reset at %s, got %s", tt.wantResetAt, r.resetAt)
+			}
+			if !r.retryAt.Equal(tt.wantRetryAt) {
+				t.Errorf("expected to retry at %s, got %s", tt.wantRetryAt, r.retryAt)
+			}
+			if got := r.throttled(); got != tt.wantThrottled {
+				t.Errorf("expected throttled to be %t, got %t", tt.wantThrottled, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"slices"
+	"sync"
+)
+
+// teamLocks serializes the updates of the members of each team within the process. Outreach replaces all the members
+// of a team on each update, so concurrent updates of the same team, on their own or within batches, would otherwise
+// overwrite each other.
+type teamLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func newTeamLocks() *teamLocks {
+	return &teamLocks{
+		locks: make(map[string]*sync.Mutex),
+	}
+}
+
+// lock locks the teams and returns the function unlocking them. The teams are locked in order, so callers locking
+// several teams can't deadlock each other.
+func (l *teamLocks) lock(teamIDs ...string) func() {
+	teamIDs = slices.Clone(teamIDs)
+	slices.Sort(teamIDs)
+	teamIDs = slices.Compact(teamIDs)
+
+	l.mu.Lock()
+	teamLocks := make([]*sync.Mutex, 0, len(teamIDs))
+	for _, teamID := range teamIDs {
+		teamLock, ok := l.locks[teamID]
+		if !ok {
+			teamLock = &sync.Mutex{}
+			l.locks[teamID] = teamLock
+		}
+		teamLocks = append(teamLocks, teamLock)
+	}
+	l.mu.Unlock()
+
+	for _, teamLock := range teamLocks {
+		teamLock.Lock()
+	}
+
+	return func() {
+		for _, teamLock := range slices.Backward(teamLocks) {
+			teamLock.Unlock()
+		}
+	}
+}
+
+// LockTeams keeps the members of the teams from being updated by other callers of this client, including the batches,
+// until the returned function is called. The members must be read and updated while the teams are locked.
+func (c *OutreachClient) LockTeams(teamIDs ...string) func() {
+	return c.teamLocks.lock(teamIDs...)
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTeamLocks(t *testing.T) {
+	locks := newTeamLocks()
+
+	unlock := locks.lock("2", "1", "2")
+
+	locked := make(chan struct{})
+	go func() {
+		defer close(locked)
+
+		unlockOther := locks.lock("1")
+		unlockOther()
+	}()
+
+	// Other teams stay available while some are locked.
+	unlockUnrelated := locks.lock("3")
+	unlockUnrelated()
+
+	select {
+	case <-locked:
+		t.Fatal("expected the team to stay locked until it is unlocked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("expected the team to be unlocked")
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+)
+
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+
+	// scopeAll grants every access on a resource, e.g. 'users.all'.
+	scopeAll = "all"
+)
+
+// TokenInfo describes the token used to communicate with Outreach, as returned by the root of the API.
+type TokenInfo struct {
+	Token struct {
+		Scopes []string `json:"scopes"`
+	} `json:"token"`
+	User struct {
+		Email   string `json:"email"`
+		OrgName string `json:"orgName"`
+		OrgGuid string `json:"orgGuid"`
+		OrgId   int    `json:"orgId"`
+	} `json:"user"`
+}
+
+// HasScope reports whether the token was granted the access ('read' or 'write') on the Outreach resource, e.g. 'users'.
+func (t *TokenInfo) HasScope(resource, access string) bool {
+	return slices.Contains(t.Token.Scopes, resource+"."+access) || slices.Contains(t.Token.Scopes, resource+"."+scopeAll)
+}
+
+// GetTokenInfo requests the root of the API, which describes the token and the organization it belongs to.
+// Requesting it also exchanges or refreshes the token when needed.
+func (c *OutreachClient) GetTokenInfo(ctx context.Context) (*TokenInfo, *v2.RateLimitDescription, error) {
+	var response struct {
+		Meta TokenInfo `json:"meta"`
+	}
+
+	rootURL, err := c.endpointURL(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rateLimitDescription := &v2.RateLimitDescription{}
+	_, err = c.doRequest(
+		ctx,
+		http.MethodGet,
+		rootURL,
+		&response,
+		nil,
+		rateLimitDescription,
+	)
+	if err != nil {
+		return nil, rateLimitDescription, err
+	}
+
+	return &response.Meta, rateLimitDescription, nil
+}
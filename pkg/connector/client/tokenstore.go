@@ -0,0 +1,261 @@
+package client
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/oauth2"
+)
+
+const (
+	tokenFileMode = 0o600
+
+	encryptedTokenVersion = 1
+	encryptionKeyLength   = 32
+	encryptionSaltLength  = 16
+
+	// scrypt parameters recommended for interactive logins.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// TokenStore persists the tokens issued by Outreach. Outreach rotates the refresh token on every refresh,
+// so the last issued one must be kept to authenticate again after a restart.
+type TokenStore interface {
+	// Load returns the stored token, or nil when no token was stored yet.
+	Load() (*oauth2.Token, error)
+	Save(token *oauth2.Token) error
+}
+
+// FileTokenStore stores the token in a file only readable by its owner.
+// When a key is given, the token is encrypted with AES-GCM using a key derived from it.
+type FileTokenStore struct {
+	path string
+	key  string
+}
+
+func NewFileTokenStore(path, key string) *FileTokenStore {
+	return &FileTokenStore{
+		path: path,
+		key:  key,
+	}
+}
+
+type encryptedToken struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func (s *FileTokenStore) Load() (*oauth2.Token, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("outreach: unable to read the token store: %w", err)
+	}
+
+	if s.key != "" {
+		content, err = s.decrypt(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var token oauth2.Token
+	err = json.Unmarshal(content, &token)
+	if err != nil {
+		return nil, fmt.Errorf("outreach: unable to parse the token store: %w", err)
+	}
+
+	return &token, nil
+}
+
+// Save writes the token to a temporary file first, so a failed write never leaves a broken token behind.
+func (s *FileTokenStore) Save(token *oauth2.Token) error {
+	content, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	if s.key != "" {
+		content, err = s.encrypt(content)
+		if err != nil {
+			return err
+		}
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("outreach: unable to write the token store: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	err = tmpFile.Chmod(tokenFileMode)
+	if err == nil {
+		_, err = tmpFile.Write(content)
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("outreach: unable to write the token store: %w", err)
+	}
+
+	err = os.Rename(tmpFile.Name(), s.path)
+	if err != nil {
+		return fmt.Errorf("outreach: unable to write the token store: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileTokenStore) gcm(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(s.key), salt, scryptN, scryptR, scryptP, encryptionKeyLength)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func (s *FileTokenStore) encrypt(plaintext []byte) ([]byte, error) {
+	salt := make([]byte, encryptionSaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := s.gcm(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(encryptedToken{
+		Version:    encryptedTokenVersion,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	})
+}
+
+func (s *FileTokenStore) decrypt(content []byte) ([]byte, error) {
+	var encrypted encryptedToken
+	err := json.Unmarshal(content, &encrypted)
+	if err != nil || encrypted.Version != encryptedTokenVersion {
+		return nil, fmt.Errorf("outreach: the token store is not encrypted with a supported format")
+	}
+
+	gcm, err := s.gcm(encrypted.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(encrypted.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("outreach: the token store is not encrypted with a supported format")
+	}
+
+	plaintext, err := gcm.Open(nil, encrypted.Nonce, encrypted.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("outreach: unable to decrypt the token store, check the key: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// storedTokenSource refreshes the token with the refresh token flow and saves every new token to the store.
+// The stored refresh token takes precedence over the configured one, since the configured one stops working
+// after the first refresh.
+type storedTokenSource struct {
+	ctx          context.Context
+	config       *oauth2.Config
+	refreshToken string
+	store        TokenStore
+
+	mu               sync.Mutex
+	source           oauth2.TokenSource
+	lastRefreshToken string
+}
+
+func (s *storedTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.source == nil {
+		token, err := s.initialToken()
+		if err != nil {
+			return nil, err
+		}
+
+		s.lastRefreshToken = token.RefreshToken
+		s.source = oauth2.ReuseTokenSource(token, s.config.TokenSource(s.ctx, token))
+	}
+
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.store != nil && token.RefreshToken != "" && token.RefreshToken != s.lastRefreshToken {
+		err = s.store.Save(token)
+		if err != nil {
+			return nil, err
+		}
+
+		s.lastRefreshToken = token.RefreshToken
+	}
+
+	return token, nil
+}
+
+func (s *storedTokenSource) initialToken() (*oauth2.Token, error) {
+	// The access token is expired on purpose, so it gets refreshed with the first request.
+	token := &oauth2.Token{
+		RefreshToken: s.refreshToken,
+		Expiry:       time.Now().Add(-1 * time.Second),
+	}
+
+	if s.store == nil {
+		return token, nil
+	}
+
+	storedToken, err := s.store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if storedToken != nil && storedToken.RefreshToken != "" {
+		return storedToken, nil
+	}
+
+	if token.RefreshToken == "" {
+		return nil, errors.New("outreach: no refresh token was configured or stored, run 'baton-outreach login' first")
+	}
+
+	return token, nil
+}
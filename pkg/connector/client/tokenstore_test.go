@@ -0,0 +1,260 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// fakeRefreshServer stands in for the Outreach token endpoint. Every refresh issues an access token expiring right
+// away, so each call to Token refreshes it again, and a new refresh token when rotate is set.
+type fakeRefreshServer struct {
+	*httptest.Server
+
+	rotate bool
+
+	mu            sync.Mutex
+	refreshTokens []string
+}
+
+func newFakeRefreshServer(t *testing.T, rotate bool) *fakeRefreshServer {
+	t.Helper()
+
+	server := &fakeRefreshServer{rotate: rotate}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get("grant_type") != "refresh_token" {
+			http.Error(w, "unexpected token request", http.StatusBadRequest)
+			return
+		}
+
+		server.mu.Lock()
+		server.refreshTokens = append(server.refreshTokens, r.PostForm.Get("refresh_token"))
+		refreshCount := len(server.refreshTokens)
+		server.mu.Unlock()
+
+		response := map[string]any{
+			"access_token": fmt.Sprintf("access-token-%d", refreshCount),
+			"token_type":   "bearer",
+			"expires_in":   1,
+		}
+		if server.rotate {
+			response["refresh_token"] = fmt.Sprintf("rotated-refresh-token-%d", refreshCount)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// usedRefreshTokens returns the refresh tokens sent to the token endpoint, in order.
+func (s *fakeRefreshServer) usedRefreshTokens() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.refreshTokens...)
+}
+
+// countingTokenStore counts the tokens saved to the file store it wraps.
+type countingTokenStore struct {
+	*FileTokenStore
+	saves int
+}
+
+func (s *countingTokenStore) Save(token *oauth2.Token) error {
+	s.saves++
+	return s.FileTokenStore.Save(token)
+}
+
+func newTestTokenSource(server *fakeRefreshServer, refreshToken string, store TokenStore) *storedTokenSource {
+	return &storedTokenSource{
+		ctx: context.Background(),
+		config: &oauth2.Config{
+			ClientID:     "client-id",
+			ClientSecret: "client-secret",
+			Endpoint: oauth2.Endpoint{
+				TokenURL: server.URL,
+			},
+		},
+		refreshToken: refreshToken,
+		store:        store,
+	}
+}
+
+func TestStoredTokenSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		rotate      bool
+		flagToken   string
+		storedToken string
+
+		wantRefreshTokens []string
+		wantStoredToken   string
+		wantSaves         int
+	}{
+		{
+			name:              "stored token preferred over the flag",
+			rotate:            true,
+			flagToken:         "flag-refresh-token",
+			storedToken:       "stored-refresh-token",
+			wantRefreshTokens: []string{"stored-refresh-token", "rotated-refresh-token-1"},
+			wantStoredToken:   "rotated-refresh-token-2",
+			wantSaves:         2,
+		},
+		{
+			name:              "flag used when nothing is stored",
+			rotate:            true,
+			flagToken:         "flag-refresh-token",
+			wantRefreshTokens: []string{"flag-refresh-token", "rotated-refresh-token-1"},
+			wantStoredToken:   "rotated-refresh-token-2",
+			wantSaves:         2,
+		},
+		{
+			name:              "refresh token not rotated",
+			flagToken:         "flag-refresh-token",
+			storedToken:       "stored-refresh-token",
+			wantRefreshTokens: []string{"stored-refresh-token", "stored-refresh-token"},
+			wantStoredToken:   "stored-refresh-token",
+			wantSaves:         0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeRefreshServer(t, tt.rotate)
+
+			store := &countingTokenStore{FileTokenStore: NewFileTokenStore(t.TempDir()+"/token.json", "")}
+			if tt.storedToken != "" {
+				err := store.FileTokenStore.Save(&oauth2.Token{
+					AccessToken:  "expired-access-token",
+					RefreshToken: tt.storedToken,
+					Expiry:       time.Now().Add(-1 * time.Hour),
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			source := newTestTokenSource(server, tt.flagToken, store)
+			for range 2 {
+				if _, err := source.Token(); err != nil {
+					t.Fatalf("unable to get a token: %v", err)
+				}
+			}
+
+			used := server.usedRefreshTokens()
+			if strings.Join(used, ",") != strings.Join(tt.wantRefreshTokens, ",") {
+				t.Errorf("expected the refresh tokens %v to be used, got %v", tt.wantRefreshTokens, used)
+			}
+
+			if store.saves != tt.wantSaves {
+				t.Errorf("expected %d saves to the token store, got %d", tt.wantSaves, store.saves)
+			}
+
+			stored, err := store.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored == nil || stored.RefreshToken != tt.wantStoredToken {
+				t.Errorf("expected the stored refresh token %s, got %+v", tt.wantStoredToken, stored)
+			}
+		})
+	}
+}
+
+func TestStoredTokenSourceWithoutRefreshToken(t *testing.T) {
+	server := newFakeRefreshServer(t, true)
+	source := newTestTokenSource(server, "", NewFileTokenStore(t.TempDir()+"/token.json", ""))
+
+	_, err := source.Token()
+	if err == nil || !strings.Contains(err.Error(), "baton-outreach login") {
+		t.Fatalf("expected an error asking to log in, got: %v", err)
+	}
+
+	if used := server.usedRefreshTokens(); len(used) != 0 {
+		t.Errorf("expected no token request, got %v", used)
+	}
+}
+
+// countingTransport counts the requests going through the HTTP client it is set on.
+type countingTransport struct {
+	mu       sync.Mutex
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.requests++
+	t.mu.Unlock()
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewWithRefreshTokenOptionOrder(t *testing.T) {
+	tests := []struct {
+		name         string
+		refreshFirst bool
+	}{
+		{
+			name:         "refresh token before the other options",
+			refreshFirst: true,
+		},
+		{
+			name: "refresh token after the other options",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeRefreshServer(t, true)
+			transport := &countingTransport{}
+			store := &countingTokenStore{FileTokenStore: NewFileTokenStore(t.TempDir()+"/token.json", "")}
+
+			opts := []ConfigOption{
+				WithTokenURL(server.URL),
+				WithHTTPClient(&http.Client{Transport: transport}),
+				WithTokenStore(store),
+			}
+			refreshOption := WithRefreshToken("client-id", "client-secret", "flag-refresh-token")
+			if tt.refreshFirst {
+				opts = append([]ConfigOption{refreshOption}, opts...)
+			} else {
+				opts = append(opts, refreshOption)
+			}
+
+			c, err := New(context.Background(), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			token, err := c.TokenSource.Token()
+			if err != nil {
+				t.Fatalf("unable to get a token: %v", err)
+			}
+
+			if token.AccessToken != "access-token-1" {
+				t.Errorf("expected the token refreshed at the token URL, got %s", token.AccessToken)
+			}
+			if transport.requests != 1 {
+				t.Errorf("expected the refresh to go through the HTTP client, got %d requests through it", transport.requests)
+			}
+			if store.saves != 1 {
+				t.Errorf("expected the rotated token to be saved to the token store, got %d saves", store.saves)
+			}
+		})
+	}
+}
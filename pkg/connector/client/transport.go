@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// httpClientTimeout matches the timeout of the clients created by the SDK.
+const httpClientTimeout = 300 * time.Second
+
+// TransportConfig configures how the connector reaches Outreach, for networks where the egress goes through a proxy
+// that re-signs TLS with its own CA, or where the client must present a certificate.
+type TransportConfig struct {
+	ProxyURL      string
+	ProxyUsername string
+	ProxyPassword string
+	// CABundlePath is a PEM file of CA certificates trusted on top of the system ones.
+	CABundlePath string
+	// ClientCertPath and ClientKeyPath are the PEM files of the certificate presented for mutual TLS.
+	ClientCertPath string
+	ClientKeyPath  string
+}
+
+// NewHTTPClient returns the HTTP client used for the API and OAuth requests. The SDK transport is used unless a proxy
+// is configured, since it always picks the proxy from the environment.
+func NewHTTPClient(ctx context.Context, config TransportConfig) (*http.Client, error) {
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	logger := ctxzap.Extract(ctx)
+
+	if config.ProxyURL == "" {
+		return uhttp.NewClient(ctx, uhttp.WithLogger(true, logger), uhttp.WithTLSClientConfig(tlsConfig))
+	}
+
+	proxyURL, err := config.proxyURL()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{
+		Timeout: httpClientTimeout,
+		Transport: &loggingTransport{
+			next:   transport,
+			logger: logger,
+		},
+	}, nil
+}
+
+// loggingTransport logs the requests like the SDK transport does with uhttp.WithLogger, for the transport used with
+// a proxy, which can't be set on the SDK one.
+type loggingTransport struct {
+	next   http.RoundTripper
+	logger *zap.Logger
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fields := []zap.Field{
+		zap.String("http.method", req.Method),
+		zap.String("http.url_details.host", req.URL.Host),
+		zap.String("http.url_details.path", req.URL.Path),
+		zap.String("http.url_details.query", req.URL.RawQuery),
+	}
+	t.logger.Debug("Request started", fields...)
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	if resp != nil {
+		fields = append(fields, zap.Int("http.status_code", resp.StatusCode))
+	}
+	t.logger.Debug("Request complete", fields...)
+
+	return resp, err
+}
+
+func (c TransportConfig) proxyURL() (*url.URL, error) {
+	proxyURL, err := url.Parse(c.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("outreach: invalid proxy URL: %w", err)
+	}
+
+	if proxyURL.Host == "" || (proxyURL.Scheme != "http" && proxyURL.Scheme != "https") {
+		return nil, fmt.Errorf("outreach: the proxy URL must be an http or https URL")
+	}
+
+	if c.ProxyUsername != "" {
+		proxyURL.User = url.UserPassword(c.ProxyUsername, c.ProxyPassword)
+	}
+
+	return proxyURL, nil
+}
+
+func (c TransportConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if c.CABundlePath != "" {
+		bundle, err := os.ReadFile(c.CABundlePath)
+		if err != nil {
+			return nil, fmt.Errorf("outreach: unable to read the CA bundle: %w", err)
+		}
+
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+
+		if !rootCAs.AppendCertsFromPEM(bundle) {
+			return nil, errors.New("outreach: the CA bundle has no PEM encoded certificate")
+		}
+
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if c.ClientCertPath != "" || c.ClientKeyPath != "" {
+		certificate, err := tls.LoadX509KeyPair(c.ClientCertPath, c.ClientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("outreach: unable to load the client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
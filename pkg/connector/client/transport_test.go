@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestHTTPClientUsesProxy(t *testing.T) {
+	var proxyAuthorization, requestedURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxyAuthorization = r.Header.Get("Proxy-Authorization")
+		requestedURL = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	core, logs := observer.New(zap.DebugLevel)
+	ctx := ctxzap.ToContext(context.Background(), zap.New(core))
+
+	httpClient, err := NewHTTPClient(ctx, TransportConfig{
+		ProxyURL:      proxy.URL,
+		ProxyUsername: "user",
+		ProxyPassword: "password",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := httpClient.Get("http://outreach.test/api/v2/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if requestedURL != "http://outreach.test/api/v2/users" {
+		t.Errorf("the request didn't go through the proxy: %s", requestedURL)
+	}
+	if proxyAuthorization != "Basic "+base64.StdEncoding.EncodeToString([]byte("user:password")) {
+		t.Errorf("unexpected proxy authorization: %s", proxyAuthorization)
+	}
+	if logs.FilterMessage("Request complete").FilterField(zap.Int("http.status_code", http.StatusOK)).Len() != 1 {
+		t.Errorf("expected the request to be logged with the logger of the context, got %v", logs.All())
+	}
+}
+
+func TestHTTPClientTrustsCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	bundlePath := filepath.Join(t.TempDir(), "ca.pem")
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundlePath, bundle, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	untrusting, err := NewHTTPClient(context.Background(), TransportConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := untrusting.Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Fatal("expected the certificate of the server to be untrusted")
+	}
+
+	trusting, err := NewHTTPClient(context.Background(), TransportConfig{CABundlePath: bundlePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := trusting.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the CA bundle to be trusted: %v", err)
+	}
+	resp.Body.Close()
+}
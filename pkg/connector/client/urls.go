@@ -0,0 +1,91 @@
+package client
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultBaseURL  = "https://api.outreach.io/api/v2"
+	defaultTokenURL = "https://api.outreach.io/oauth/token"
+
+	// The other endpoints are found relative to the API base URL and the token URL, so they follow them to other hosts.
+	appInstallsRef = "../app/installs"
+	authorizeRef   = "authorize"
+)
+
+// ValidateURL checks that the URL can be used to reach Outreach: it must be absolute, and use HTTPS unless it points
+// to the loopback interface, like a local mock of the API.
+func ValidateURL(rawURL string) (*url.URL, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "outreach: invalid URL %q: %s", rawURL, err)
+	}
+
+	if !parsedURL.IsAbs() || parsedURL.Host == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "outreach: the URL %q must be absolute", rawURL)
+	}
+
+	switch strings.ToLower(parsedURL.Scheme) {
+	case "https":
+	case "http":
+		if !isLoopback(parsedURL.Hostname()) {
+			return nil, status.Errorf(codes.InvalidArgument, "outreach: the URL %q must use HTTPS", rawURL)
+		}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "outreach: the URL %q must use HTTPS", rawURL)
+	}
+
+	return parsedURL, nil
+}
+
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkOrigin makes sure the URL points to the origin of the Outreach API.
+func checkOrigin(apiURL, requestURL *url.URL) error {
+	if !strings.EqualFold(requestURL.Scheme, apiURL.Scheme) || !strings.EqualFold(requestURL.Host, apiURL.Host) {
+		return status.Errorf(codes.InvalidArgument, "outreach: refusing to send a request to %s://%s, outside of the Outreach API", requestURL.Scheme, requestURL.Host)
+	}
+
+	return nil
+}
+
+// resolveURL resolves a reference relative to a directory of the base URL.
+func resolveURL(baseURL *url.URL, ref string) string {
+	dirURL := *baseURL
+	if !strings.HasSuffix(dirURL.Path, "/") {
+		dirURL.Path += "/"
+	}
+
+	return dirURL.ResolveReference(&url.URL{Path: ref}).String()
+}
+
+// OAuthEndpoint returns the OAuth endpoints of Outreach for the token URL. An empty token URL uses the Outreach one,
+// and the authorization URL is found next to the token URL.
+func OAuthEndpoint(tokenURL string) (oauth2.Endpoint, error) {
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+
+	parsedURL, err := ValidateURL(tokenURL)
+	if err != nil {
+		return oauth2.Endpoint{}, err
+	}
+
+	return oauth2.Endpoint{
+		AuthURL:  parsedURL.ResolveReference(&url.URL{Path: authorizeRef}).String(),
+		TokenURL: parsedURL.String(),
+	}, nil
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		rawURL string
+		valid  bool
+	}{
+		{rawURL: "https://api.outreach.io/api/v2", valid: true},
+		{rawURL: "https://api.eu.outreach.io/api/v2", valid: true},
+		{rawURL: "http://127.0.0.1:8080/api/v2", valid: true},
+		{rawURL: "http://localhost:8080", valid: true},
+		{rawURL: "http://[::1]:8080", valid: true},
+		{rawURL: "http://api.outreach.io/api/v2", valid: false},
+		{rawURL: "ftp://api.outreach.io", valid: false},
+		{rawURL: "api.outreach.io/api/v2", valid: false},
+		{rawURL: "https://", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rawURL, func(t *testing.T) {
+			_, err := ValidateURL(tt.rawURL)
+			if tt.valid && err != nil {
+				t.Errorf("expected the URL to be valid, got: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("expected the URL to be invalid")
+			}
+		})
+	}
+}
+
+func TestRelatedURLs(t *testing.T) {
+	apiURL, err := url.Parse("https://proxy.example.com/outreach/api/v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if installsURL := resolveURL(apiURL, appInstallsRef); installsURL != "https://proxy.example.com/outreach/api/app/installs" {
+		t.Errorf("unexpected app installs URL: %s", installsURL)
+	}
+
+	endpoint, err := OAuthEndpoint("https://proxy.example.com/outreach/oauth/token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if endpoint.AuthURL != "https://proxy.example.com/outreach/oauth/authorize" {
+		t.Errorf("unexpected authorization URL: %s", endpoint.AuthURL)
+	}
+}
@@ -2,26 +2,65 @@ package connector
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/conductorone/baton-outreach/pkg/connector/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
 	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
+// scopedResources are the Outreach resources the connector always needs to read. The resources of the optional
+// resource types are in optionalReadScopes.
+var scopedResources = []string{"users", "teams", "profiles"}
+
 type Connector struct {
 	client *client.OutreachClient
+
+	tokenInfo   *client.TokenInfo
+	tokenInfoMu sync.Mutex
+
+	// provisioning tells which resource types were registered, and whether their provisioning capabilities were.
+	provisioning   map[string]bool
+	provisioningMu sync.Mutex
+
+	clientOptions    []client.ConfigOption
+	fullSyncInterval time.Duration
+	teamFetchWorkers int
+	readOnly         bool
+	// fallbackProfile names the profile users are moved to when their profile is revoked, instead of the 'Default' one.
+	fallbackProfile        string
+	restorePreviousProfile bool
 }
 
 // ResourceSyncers returns a ResourceSyncer for each resource type that should be synced from the upstream service.
-func (d *Connector) ResourceSyncers(_ context.Context) []connectorbuilder.ResourceSyncer {
-	return []connectorbuilder.ResourceSyncer{
-		newUserBuilder(d.client),
-		newTeamBuilder(d.client),
-		newProfileBuilder(d.client),
-	}
+// Builders are only registered with their provisioning capabilities when the token is allowed to use them.
+// The roles of the users are only requested and granted when the role resource type is synced, and the team memberships
+// are taken from the teams of the listed users.
+func (d *Connector) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
+	tokenInfo := d.registrationTokenInfo(ctx)
+	users := newListedUsers()
+
+	return d.provisionableSyncers(
+		ctx,
+		tokenInfo,
+		newUserBuilder(d.client, users, d.fullSyncInterval, canRead(roleResourceType.Id, tokenInfo)),
+		newTeamBuilder(d.client, users, d.fullSyncInterval, d.teamFetchWorkers),
+		newProfileBuilder(d.client, d.fullSyncInterval, d.fallbackProfile, d.restorePreviousProfile),
+		newRoleBuilder(d.client),
+		newMailboxBuilder(d.client),
+		newSequenceBuilder(d.client),
+	)
 }
 
 // Asset takes an input AssetRef and attempts to fetch it using the connector's authenticated http client
@@ -31,10 +70,28 @@ func (d *Connector) Asset(_ context.Context, _ *v2.AssetRef) (string, io.ReadClo
 }
 
 // Metadata returns metadata about the connector.
-func (d *Connector) Metadata(_ context.Context) (*v2.ConnectorMetadata, error) {
+// The identity of the Outreach organization is added to the profile when the token can be used.
+func (d *Connector) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
+	var profile *structpb.Struct
+
+	tokenInfo, err := d.getTokenInfo(ctx)
+	if err != nil {
+		ctxzap.Extract(ctx).Warn("unable to get the Outreach organization", zap.Error(err))
+	} else {
+		profile, err = structpb.NewStruct(map[string]interface{}{
+			"org_name": tokenInfo.User.OrgName,
+			"org_guid": tokenInfo.User.OrgGuid,
+			"org_id":   tokenInfo.User.OrgId,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &v2.ConnectorMetadata{
+		Profile:     profile,
 		DisplayName: "Outreach",
-		Description: "Baton connector to sync users, teams and profiles from Outreach",
+		Description: "Baton connector to sync users, teams, profiles, roles, mailboxes and sequences from Outreach",
 		AccountCreationSchema: &v2.ConnectorAccountCreationSchema{
 			FieldMap: map[string]*v2.ConnectorAccountCreationSchema_Field{
 				"first_name": {
@@ -64,46 +121,160 @@ func (d *Connector) Metadata(_ context.Context) (*v2.ConnectorMetadata, error) {
 
 // Validate is called to ensure that the connector is properly configured. It should exercise any API credentials
 // to be sure that they are valid.
-func (d *Connector) Validate(_ context.Context) (annotations.Annotations, error) {
-	return nil, nil
-}
+// The token is exchanged or refreshed if needed, and the scopes granted to it are checked against the ones the connector uses
+// to sync and to provision the resource types registered with provisioning capabilities.
+func (d *Connector) Validate(ctx context.Context) (annotations.Annotations, error) {
+	outAnnotations := annotations.Annotations{}
 
-// NewWithAccessToken returns a new instance of the connector created for CLI one-shot executions.
-func NewWithAccessToken(ctx context.Context, accessToken string) (*Connector, error) {
-	c, err := client.New(ctx, client.WithAccessToken(accessToken))
+	tokenInfo, rateLimitData, err := d.client.GetTokenInfo(ctx)
 	if err != nil {
-		return nil, err
+		if rateLimitData != nil {
+			outAnnotations.WithRateLimiting(rateLimitData)
+		}
+		return outAnnotations, fmt.Errorf("outreach: unable to validate the credentials: %w", err)
 	}
 
-	return &Connector{
-		client: c,
-	}, nil
+	d.setTokenInfo(tokenInfo)
+
+	var missingScopes []string
+	for _, resource := range d.requiredReadScopes() {
+		if !tokenInfo.HasScope(resource, client.ScopeRead) {
+			missingScopes = append(missingScopes, fmt.Sprintf("%s.%s", resource, client.ScopeRead))
+		}
+	}
+
+	for _, resource := range d.requiredWriteScopes() {
+		if !tokenInfo.HasScope(resource, client.ScopeWrite) {
+			missingScopes = append(missingScopes, fmt.Sprintf("%s.%s", resource, client.ScopeWrite))
+		}
+	}
+
+	if len(missingScopes) > 0 {
+		return outAnnotations, status.Errorf(
+			codes.PermissionDenied,
+			"outreach: the token for the organization '%s' is missing the scopes: %s (granted scopes: %s)",
+			tokenInfo.User.OrgName,
+			strings.Join(missingScopes, ", "),
+			strings.Join(tokenInfo.Token.Scopes, ", "),
+		)
+	}
+
+	ctxzap.Extract(ctx).Info(
+		"outreach credentials validated",
+		zap.String("org_name", tokenInfo.User.OrgName),
+		zap.Strings("scopes", tokenInfo.Token.Scopes),
+	)
+
+	return outAnnotations, nil
 }
 
-// NewWithRefreshToken returns a new instance of the connector created for CLI with automatic token refresh.
-func NewWithRefreshToken(ctx context.Context, clientID, clientSecret, refreshToken string) (*Connector, error) {
-	c, err := client.New(ctx, client.WithRefreshToken(ctx, clientID, clientSecret, refreshToken))
+func (d *Connector) setTokenInfo(tokenInfo *client.TokenInfo) {
+	d.tokenInfoMu.Lock()
+	defer d.tokenInfoMu.Unlock()
+
+	d.tokenInfo = tokenInfo
+}
+
+// getTokenInfo returns the token information found by Validate, requesting it when Validate wasn't called yet.
+func (d *Connector) getTokenInfo(ctx context.Context) (*client.TokenInfo, error) {
+	d.tokenInfoMu.Lock()
+	defer d.tokenInfoMu.Unlock()
+
+	if d.tokenInfo != nil {
+		return d.tokenInfo, nil
+	}
+
+	tokenInfo, _, err := d.client.GetTokenInfo(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Connector{
-		client: c,
-	}, nil
+	d.tokenInfo = tokenInfo
+
+	return tokenInfo, nil
+}
+
+// Option configures the connector.
+type Option func(*Connector)
+
+// WithClientOptions sets the options used to create the Outreach client, besides the authentication.
+func WithClientOptions(opts ...client.ConfigOption) Option {
+	return func(d *Connector) {
+		d.clientOptions = append(d.clientOptions, opts...)
+	}
+}
+
+// WithFullSyncInterval sets how often the users, teams and profiles are listed in full. In between, only the ones
+// updated since the previous sync are requested, and the others are reused from it. Zero always lists them in full.
+func WithFullSyncInterval(interval time.Duration) Option {
+	return func(d *Connector) {
+		d.fullSyncInterval = interval
+	}
+}
+
+// WithTeamFetchWorkers sets how many teams are requested concurrently while syncing the team memberships.
+// Zero or less requests every team from Grants, one at a time.
+func WithTeamFetchWorkers(workers int) Option {
+	return func(d *Connector) {
+		d.teamFetchWorkers = workers
+	}
+}
+
+// WithFallbackProfile sets the name of the profile users are moved to when their profile is revoked.
+// An empty name keeps the 'Default' profile of the organization.
+func WithFallbackProfile(name string) Option {
+	return func(d *Connector) {
+		d.fallbackProfile = name
+	}
+}
+
+// WithRestorePreviousProfile moves users back to the profile they had before a profile grant when it is revoked,
+// instead of the fallback profile.
+func WithRestorePreviousProfile(restore bool) Option {
+	return func(d *Connector) {
+		d.restorePreviousProfile = restore
+	}
+}
+
+// WithReadOnly disables the provisioning capabilities of every resource type.
+func WithReadOnly(readOnly bool) Option {
+	return func(d *Connector) {
+		d.readOnly = readOnly
+	}
+}
+
+// NewWithAccessToken returns a new instance of the connector created for CLI one-shot executions.
+func NewWithAccessToken(ctx context.Context, accessToken string, opts ...Option) (*Connector, error) {
+	return newConnector(ctx, client.WithAccessToken(accessToken), opts...)
+}
+
+// NewWithRefreshToken returns a new instance of the connector created for CLI with automatic token refresh.
+func NewWithRefreshToken(ctx context.Context, clientID, clientSecret, refreshToken string, opts ...Option) (*Connector, error) {
+	return newConnector(ctx, client.WithRefreshToken(clientID, clientSecret, refreshToken), opts...)
 }
 
 // NewWithTokenSource returns a new instance of the connector using a provided Token Source.
-func NewWithTokenSource(ctx context.Context, tokenSource oauth2.TokenSource) (*Connector, error) {
-	clientOptions := []client.ConfigOption{
-		client.WithTokenSource(tokenSource),
+func NewWithTokenSource(ctx context.Context, tokenSource oauth2.TokenSource, opts ...Option) (*Connector, error) {
+	return newConnector(ctx, client.WithTokenSource(tokenSource), opts...)
+}
+
+func newConnector(ctx context.Context, authOption client.ConfigOption, opts ...Option) (*Connector, error) {
+	d := &Connector{
+		provisioning:     make(map[string]bool),
+		teamFetchWorkers: DefaultTeamFetchWorkers,
+	}
+	for _, opt := range opts {
+		opt(d)
 	}
 
+	clientOptions := append(d.clientOptions, authOption)
+
 	c, err := client.New(ctx, clientOptions...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Connector{
-		client: c,
-	}, nil
+	d.client = c
+
+	return d, nil
 }
@@ -0,0 +1,86 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-outreach/pkg/outreachtest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConnectorValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		// scopesUnknown makes the syncers be registered before the scopes of the token are known.
+		scopesUnknown bool
+		wantCode      codes.Code
+		wantMissing   string
+	}{
+		{
+			name:   "every scope",
+			scopes: outreachtest.DefaultScopes,
+		},
+		{
+			name:   "optional scopes missing",
+			scopes: []string{"users.all", "teams.all", "profiles.read"},
+		},
+		{
+			name:        "required scopes missing",
+			scopes:      []string{"users.all", "roles.read"},
+			wantCode:    codes.PermissionDenied,
+			wantMissing: "teams.read, profiles.read",
+		},
+		{
+			name:          "scopes of the syncers registered without knowing the scopes",
+			scopes:        []string{"users.read", "teams.read", "profiles.read"},
+			scopesUnknown: true,
+			wantCode:      codes.PermissionDenied,
+			wantMissing:   "mailboxes.read, roles.read, sequences.read, sequences.write, teams.write, users.write",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, d := newTestConnector(t, tt.scopes)
+
+			if tt.scopesUnknown {
+				server.Fail(http.MethodGet, "", http.StatusServiceUnavailable, 1)
+			}
+			d.ResourceSyncers(ctx)
+
+			_, err := d.Validate(ctx)
+			checkCode(t, err, tt.wantCode)
+
+			if err == nil {
+				return
+			}
+
+			message := status.Convert(err).Message()
+			want := "outreach: the token for the organization 'Outreach Test' is missing the scopes: " + tt.wantMissing +
+				" (granted scopes: " + strings.Join(tt.scopes, ", ") + ")"
+			if message != want {
+				t.Errorf("expected the message %q, got %q", want, message)
+			}
+		})
+	}
+}
+
+func TestConnectorMetadata(t *testing.T) {
+	ctx := context.Background()
+	_, d := newTestConnector(t, outreachtest.DefaultScopes)
+
+	metadata, err := d.Metadata(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profile := metadata.Profile.AsMap()
+	if profile["org_name"] == nil || profile["org_guid"] == nil || profile["org_id"] == nil {
+		t.Errorf("expected the organization in the profile, got %v", profile)
+	}
+}
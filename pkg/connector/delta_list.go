@@ -0,0 +1,301 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+const (
+	// checkpointClockSkew is subtracted from the checkpoints, so changes are not missed because of clock differences with Outreach.
+	checkpointClockSkew = 5 * time.Minute
+	// unchangedPageSize is the number of resources per page when returning the ones unchanged since the previous list.
+	unchangedPageSize = client.DefaultPageSize
+
+	// The page tokens of a delta list carry the phase it is in, along with its checkpoint, like "updated:2024-01-01T10:00:00Z".
+	// The phase is empty while listing in full.
+	deltaPhaseUpdated   = "updated"
+	deltaPhaseUnchanged = "unchanged"
+)
+
+// listPageFunc requests a page of the resources updated within the range.
+type listPageFunc[T any] func(ctx context.Context, cursor string, updatedAt client.UpdatedAtRange) ([]*T, string, *v2.RateLimitDescription, error)
+
+// deltaList lists a resource type keeping the resources of the previous list in memory, so the next lists only request
+// the resources updated since its checkpoint with the 'filter[updatedAt]' filter, and return the others as they were.
+// The resources are listed in full when nothing was listed before, like in a new process, and once the last full list
+// is older than the full sync interval, which drops the resources deleted since. A zero interval always lists them in full.
+// The requests skip the response cache, since the resources must not be older than the checkpoint taken when listing them.
+type deltaList[T any] struct {
+	resourceTypeID   string
+	fullSyncInterval time.Duration
+	listPage         listPageFunc[T]
+	id               func(*T) int
+
+	mu sync.Mutex
+	// previous has the resources of the last complete list, with the updates made since checkpoint missing,
+	// and fullListedAt is when the last full list started.
+	previous     map[int]*T
+	checkpoint   time.Time
+	fullListedAt time.Time
+	// updatedSince is the checkpoint the last complete list requested the updates from, zero when it was a full list.
+	updatedSince time.Time
+
+	// current collects the resources of the list in progress, started at startedAt. The resources of the previous list
+	// which weren't updated are returned in the order of unchanged.
+	current   map[int]*T
+	unchanged []int
+	startedAt time.Time
+	full      bool
+}
+
+func newDeltaList[T any](resourceTypeID string, fullSyncInterval time.Duration, listPage listPageFunc[T], id func(*T) int) *deltaList[T] {
+	return &deltaList[T]{
+		resourceTypeID:   resourceTypeID,
+		fullSyncInterval: fullSyncInterval,
+		listPage:         listPage,
+		id:               id,
+	}
+}
+
+// page returns a page of the resources, and the token of the next one. A list found in the middle of a delta without
+// the previous resources, like after a restart, is started over in full.
+func (d *deltaList[T]) page(ctx context.Context, token string) ([]*T, string, *v2.RateLimitDescription, error) {
+	bag, cursor, err := client.GetToken(token, &v2.ResourceId{ResourceType: d.resourceTypeID})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if token == "" {
+		checkpoint, full := d.start(false)
+		if full {
+			return d.fullPage(ctx, bag, "")
+		}
+		return d.updatedPage(ctx, bag, checkpoint, "")
+	}
+
+	phase, checkpoint, err := parseDeltaPhase(bag.ResourceID())
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if phase != "" && !d.resumable(checkpoint) {
+		d.start(true)
+		return d.fullPage(ctx, &pagination.Bag{}, "")
+	}
+
+	switch phase {
+	case deltaPhaseUpdated:
+		return d.updatedPage(ctx, bag, checkpoint, cursor)
+	case deltaPhaseUnchanged:
+		offset, err := strconv.Atoi(cursor)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("outreach: invalid page token for the unchanged %s: %w", d.resourceTypeID, err)
+		}
+		return d.unchangedPage(bag, checkpoint, offset)
+	default:
+		return d.fullPage(ctx, bag, cursor)
+	}
+}
+
+// updatedSinceLastList returns the checkpoint the last complete list requested the updates from, or zero when it
+// listed the resources in full.
+func (d *deltaList[T]) updatedSinceLastList() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.updatedSince
+}
+
+// start begins a new list, in full when forced, when nothing was listed before or when the full sync interval elapsed.
+// It returns the checkpoint to request the updates from otherwise.
+func (d *deltaList[T]) start(forceFull bool) (time.Time, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	d.full = forceFull || d.previous == nil || d.fullSyncInterval <= 0 || now.Sub(d.fullListedAt) >= d.fullSyncInterval
+	d.unchanged = nil
+	// Nothing is kept when the resources are always listed in full.
+	d.current = nil
+	if d.fullSyncInterval > 0 {
+		d.current = make(map[int]*T)
+	}
+	d.startedAt = now
+
+	return d.checkpoint, d.full
+}
+
+// resumable reports whether the previous resources the delta list at the checkpoint continues from are still kept.
+func (d *deltaList[T]) resumable(checkpoint time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.current != nil && d.previous != nil && d.checkpoint.Equal(checkpoint)
+}
+
+func (d *deltaList[T]) fullPage(ctx context.Context, bag *pagination.Bag, cursor string) ([]*T, string, *v2.RateLimitDescription, error) {
+	resources, nextCursor, rateLimitData, err := d.listPage(client.ContextWithFreshRead(ctx), cursor, client.UpdatedAtRange{})
+	if err != nil {
+		return nil, "", rateLimitData, err
+	}
+
+	d.add(resources)
+	if nextCursor == "" {
+		d.finish()
+		return resources, "", rateLimitData, nil
+	}
+
+	nextPageToken, err := nextDeltaToken(bag, d.resourceTypeID, "", time.Time{}, nextCursor)
+	if err != nil {
+		return nil, "", rateLimitData, err
+	}
+
+	return resources, nextPageToken, rateLimitData, nil
+}
+
+// updatedPage requests a page of the resources updated since the checkpoint. The unchanged resources are returned
+// after the last one.
+func (d *deltaList[T]) updatedPage(
+	ctx context.Context,
+	bag *pagination.Bag,
+	checkpoint time.Time,
+	cursor string,
+) ([]*T, string, *v2.RateLimitDescription, error) {
+	resources, nextCursor, rateLimitData, err := d.listPage(client.ContextWithFreshRead(ctx), cursor, client.UpdatedAtRange{From: checkpoint})
+	if err != nil {
+		return nil, "", rateLimitData, err
+	}
+
+	d.add(resources)
+
+	var nextPageToken string
+	if nextCursor != "" {
+		nextPageToken, err = nextDeltaToken(bag, d.resourceTypeID, deltaPhaseUpdated, checkpoint, nextCursor)
+	} else {
+		d.collectUnchanged()
+		nextPageToken, err = nextDeltaToken(bag, d.resourceTypeID, deltaPhaseUnchanged, checkpoint, "0")
+	}
+	if err != nil {
+		return nil, "", rateLimitData, err
+	}
+
+	return resources, nextPageToken, rateLimitData, nil
+}
+
+// unchangedPage returns a page of the previous resources which weren't updated since the checkpoint.
+func (d *deltaList[T]) unchangedPage(bag *pagination.Bag, checkpoint time.Time, offset int) ([]*T, string, *v2.RateLimitDescription, error) {
+	d.mu.Lock()
+	end := min(offset+unchangedPageSize, len(d.unchanged))
+	resources := make([]*T, 0, max(end-offset, 0))
+	for _, id := range d.unchanged[min(offset, end):end] {
+		resources = append(resources, d.previous[id])
+		d.current[id] = d.previous[id]
+	}
+	remaining := end < len(d.unchanged)
+	d.mu.Unlock()
+
+	if !remaining {
+		d.finish()
+		return resources, "", nil, nil
+	}
+
+	nextPageToken, err := nextDeltaToken(bag, d.resourceTypeID, deltaPhaseUnchanged, checkpoint, strconv.Itoa(end))
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return resources, nextPageToken, nil, nil
+}
+
+// add keeps the listed resources for the next list. Nothing is kept for a list resumed without its first pages.
+func (d *deltaList[T]) add(resources []*T) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.current == nil {
+		return
+	}
+
+	for _, resource := range resources {
+		d.current[d.id(resource)] = resource
+	}
+}
+
+// collectUnchanged sorts out the previous resources which weren't updated, once all the updated ones are listed.
+func (d *deltaList[T]) collectUnchanged() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.unchanged = d.unchanged[:0]
+	for id := range d.previous {
+		if _, ok := d.current[id]; !ok {
+			d.unchanged = append(d.unchanged, id)
+		}
+	}
+	slices.Sort(d.unchanged)
+}
+
+// finish keeps the resources of the list that just completed for the next one, taking its start as the checkpoint.
+func (d *deltaList[T]) finish() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.current == nil {
+		return
+	}
+
+	if d.full {
+		d.fullListedAt = d.startedAt
+		d.updatedSince = time.Time{}
+	} else {
+		d.updatedSince = d.checkpoint
+	}
+
+	d.previous = d.current
+	d.checkpoint = d.startedAt.Add(-checkpointClockSkew).Truncate(time.Second)
+	d.current = nil
+	d.unchanged = nil
+}
+
+// nextDeltaToken returns the page token of the next page, in the given phase of the list.
+func nextDeltaToken(bag *pagination.Bag, resourceTypeID, phase string, checkpoint time.Time, cursor string) (string, error) {
+	bag.Pop()
+	bag.Push(pagination.PageState{
+		ResourceTypeID: resourceTypeID,
+		ResourceID:     formatDeltaPhase(phase, checkpoint),
+		Token:          cursor,
+	})
+
+	return bag.Marshal()
+}
+
+func formatDeltaPhase(phase string, checkpoint time.Time) string {
+	if phase == "" {
+		return ""
+	}
+
+	return phase + ":" + checkpoint.UTC().Format(time.RFC3339)
+}
+
+func parseDeltaPhase(value string) (string, time.Time, error) {
+	if value == "" {
+		return "", time.Time{}, nil
+	}
+
+	phase, checkpointValue, _ := strings.Cut(value, ":")
+	checkpoint, err := time.Parse(time.RFC3339, checkpointValue)
+	if err != nil || (phase != deltaPhaseUpdated && phase != deltaPhaseUnchanged) {
+		return "", time.Time{}, fmt.Errorf("outreach: invalid page token phase %q", value)
+	}
+
+	return phase, checkpoint, nil
+}
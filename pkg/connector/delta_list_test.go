@@ -0,0 +1,114 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+)
+
+func TestDeltaList(t *testing.T) {
+	tests := []struct {
+		name             string
+		fullSyncInterval time.Duration
+		// fullListAge ages the first list, as if it was made that long ago.
+		fullListAge time.Duration
+		// resumed continues the second list after its first page in a new process, without the previous profiles.
+		resumed      bool
+		wantFiltered bool
+	}{
+		{
+			name:             "updated since the previous list",
+			fullSyncInterval: 24 * time.Hour,
+			wantFiltered:     true,
+		},
+		{
+			name:             "full sync interval elapsed",
+			fullSyncInterval: 24 * time.Hour,
+			fullListAge:      25 * time.Hour,
+		},
+		{
+			name: "full sync interval disabled",
+		},
+		{
+			name:             "resumed without the previous list",
+			fullSyncInterval: 24 * time.Hour,
+			resumed:          true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t, client.WithPageSize(1))
+			profileID := func(profile *client.Profile) int {
+				return profile.Id
+			}
+
+			profiles := newDeltaList(profileResourceType.Id, tt.fullSyncInterval, c.ListAllProfiles, profileID)
+			listProfiles(ctx, t, profiles, "")
+			profiles.fullListedAt = profiles.fullListedAt.Add(-tt.fullListAge)
+
+			err := server.Seed(client.ResourceObject{
+				Id:         2,
+				Type:       "profile",
+				Attributes: []byte(`{"name":"Everyone","updatedAt":"` + time.Now().UTC().Format(time.RFC3339) + `"}`),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var listed []*client.Profile
+			if tt.resumed {
+				page, token, _, err := profiles.page(ctx, "")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				listed = append(page, listProfiles(ctx, t, newDeltaList(profileResourceType.Id, tt.fullSyncInterval, c.ListAllProfiles, profileID), token)...)
+			} else {
+				listed = listProfiles(ctx, t, profiles, "")
+			}
+
+			names := make(map[int]string)
+			for _, profile := range listed {
+				names[profile.Id] = profile.Attributes.Name
+			}
+			want := map[int]string{1: "Admin", 2: "Everyone", 3: "Sales Rep"}
+			if len(names) != len(want) || names[1] != want[1] || names[2] != want[2] || names[3] != want[3] {
+				t.Errorf("expected the profiles %v, got %v", want, names)
+			}
+
+			var filtered bool
+			for _, request := range server.Requests() {
+				if request.Method == http.MethodGet && request.Path == "/profiles" {
+					filtered = request.Query.Has("filter[updatedAt]")
+				}
+			}
+			if filtered != tt.wantFiltered {
+				t.Errorf("expected the last profiles to be requested by update: %t, got %t", tt.wantFiltered, filtered)
+			}
+		})
+	}
+}
+
+// listProfiles returns the profiles of every page, starting from the page token.
+func listProfiles(ctx context.Context, t *testing.T, profiles *deltaList[client.Profile], token string) []*client.Profile {
+	t.Helper()
+
+	var listed []*client.Profile
+	for {
+		page, nextToken, _, err := profiles.page(ctx, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		listed = append(listed, page...)
+		if nextToken == "" {
+			return listed
+		}
+		token = nextToken
+	}
+}
@@ -0,0 +1,228 @@
+package connector
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	"github.com/conductorone/baton-outreach/pkg/outreachtest"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestServer starts a fake Outreach API seeded with the default fixtures, and returns a client of it.
+// Every test case gets its own server and client, since the client caches the GET responses.
+func newTestServer(t *testing.T, opts ...client.ConfigOption) (*outreachtest.Server, *client.OutreachClient) {
+	t.Helper()
+
+	server, err := outreachtest.NewServer(outreachtest.DefaultFixtures())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	c, err := server.NewClient(context.Background(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return server, c
+}
+
+func resourceOf(resourceType *v2.ResourceType, id string) *v2.Resource {
+	return &v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: resourceType.Id,
+			Resource:     id,
+		},
+	}
+}
+
+func entitlementOf(resource *v2.Resource, permission string) *v2.Entitlement {
+	return &v2.Entitlement{
+		Id:       entitlement.NewEntitlementID(resource, permission),
+		Resource: resource,
+		Slug:     permission,
+	}
+}
+
+func resourceIDs(resources []*v2.Resource) []string {
+	ids := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		ids = append(ids, resource.Id.Resource)
+	}
+
+	return ids
+}
+
+func principalIDs(grants []*v2.Grant) []string {
+	ids := make([]string, 0, len(grants))
+	for _, grant := range grants {
+		ids = append(ids, grant.Principal.Id.Resource)
+	}
+
+	return ids
+}
+
+func checkCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+
+	if want == codes.OK {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+
+	if got := status.Code(err); got != want {
+		t.Fatalf("expected the code %s, got %s (error: %v)", want, got, err)
+	}
+}
+
+func checkTeamMembers(t *testing.T, server *outreachtest.Server, teamID string, want []int) {
+	t.Helper()
+
+	teamIDNumber, err := strconv.Atoi(teamID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var team client.Team
+	if !server.Resource("team", teamIDNumber, &team) {
+		t.Fatalf("the team %s is missing", teamID)
+	}
+
+	var got []int
+	if team.Relationships != nil && team.Relationships.Users != nil && team.Relationships.Users.Data != nil {
+		for _, member := range *team.Relationships.Users.Data {
+			got = append(got, member.Id)
+		}
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("expected the members %v, got %v", want, got)
+	}
+}
+
+// checkRelationship checks the ID of the resource the fake API relates to the resource of the kind, like the profile of
+// a user, as returned by related. 0 means that it has none.
+func checkRelationship[T any](t *testing.T, server *outreachtest.Server, kind, id string, related func(*T) int, want int) {
+	t.Helper()
+
+	idNumber, err := strconv.Atoi(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var resource T
+	if !server.Resource(kind, idNumber, &resource) {
+		t.Fatalf("the %s %s is missing", kind, id)
+	}
+
+	if got := related(&resource); got != want {
+		t.Errorf("expected the %s %s to be related to %d, got %d", kind, id, want, got)
+	}
+}
+
+// userProfile returns the ID of the profile of the user, or 0 when it has none.
+func userProfile(user *client.User) int {
+	if user.Relationships == nil || user.Relationships.Profile == nil || user.Relationships.Profile.Data == nil {
+		return 0
+	}
+
+	return user.Relationships.Profile.Data.Id
+}
+
+// userRole returns the ID of the role of the user, or 0 when it has none.
+func userRole(user *client.User) int {
+	if user.Relationships == nil || user.Relationships.Role == nil || user.Relationships.Role.Data == nil {
+		return 0
+	}
+
+	return user.Relationships.Role.Data.Id
+}
+
+// newTestConnector returns a connector using the fake API, whose token has the given scopes.
+func newTestConnector(t *testing.T, scopes []string, opts ...Option) (*outreachtest.Server, *Connector) {
+	t.Helper()
+
+	server, err := outreachtest.NewServer(outreachtest.DefaultFixtures())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+	server.SetScopes(scopes...)
+
+	opts = append(opts, WithClientOptions(client.WithBaseURL(server.URL)))
+	d, err := NewWithAccessToken(context.Background(), outreachtest.DefaultToken, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return server, d
+}
+
+// syncerTypes returns the resource types of the syncers, suffixed with " (read-only)" when their provisioning
+// capabilities are hidden.
+func syncerTypes(ctx context.Context, syncers []connectorbuilder.ResourceSyncer) []string {
+	types := make([]string, 0, len(syncers))
+	for _, syncer := range syncers {
+		resourceType := syncer.ResourceType(ctx).Id
+		if _, ok := syncer.(*readOnlySyncer); ok {
+			resourceType += " (read-only)"
+		}
+		types = append(types, resourceType)
+	}
+
+	return types
+}
+
+// listAll lists every page of the resources of the syncer, like a sync does.
+func listAll(ctx context.Context, t *testing.T, syncer connectorbuilder.ResourceSyncer) []*v2.Resource {
+	t.Helper()
+
+	var (
+		resources []*v2.Resource
+		token     string
+	)
+	for {
+		page, nextToken, _, err := syncer.List(ctx, nil, &pagination.Token{Token: token})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resources = append(resources, page...)
+		if nextToken == "" {
+			return resources
+		}
+		token = nextToken
+	}
+}
+
+// grantsOf returns every page of the grants of the resource.
+func grantsOf(ctx context.Context, t *testing.T, syncer connectorbuilder.ResourceSyncer, resource *v2.Resource) []*v2.Grant {
+	t.Helper()
+
+	var (
+		grants []*v2.Grant
+		token  string
+	)
+	for {
+		page, nextToken, _, err := syncer.Grants(ctx, resource, &pagination.Token{Token: token})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		grants = append(grants, page...)
+		if nextToken == "" {
+			return grants
+		}
+		token = nextToken
+	}
+}
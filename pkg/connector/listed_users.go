@@ -0,0 +1,125 @@
+package connector
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+)
+
+// listedUsers keeps the relationships of the users listed by the current sync, so the grants of the users and of the
+// teams are built from them instead of requesting every user and team again. It is reset when the users are listed
+// from the first page, and the members of the teams are only known once every user was listed with all its teams.
+type listedUsers struct {
+	mu            sync.RWMutex
+	relationships map[string]*client.UserRelationships
+	teamMembers   map[string][]string
+	// listed tells whether the last page of users was listed since the reset, and missingTeams whether some of the
+	// teams of the users are unknown.
+	listed       bool
+	missingTeams bool
+	// updatedSince is the checkpoint the updated users were listed from, when the others were reused from the previous
+	// list. It is zero when every user was requested.
+	updatedSince time.Time
+}
+
+func newListedUsers() *listedUsers {
+	return &listedUsers{
+		relationships: make(map[string]*client.UserRelationships),
+		teamMembers:   make(map[string][]string),
+	}
+}
+
+// reset drops the users listed before, when a new sync starts listing the users.
+func (u *listedUsers) reset() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.relationships = make(map[string]*client.UserRelationships)
+	u.teamMembers = make(map[string][]string)
+	u.listed = false
+	u.missingTeams = false
+	u.updatedSince = time.Time{}
+}
+
+// add keeps the relationships of a listed user. The teams of the user are unknown when Outreach embedded only some
+// of them.
+func (u *listedUsers) add(userID string, relationships *client.UserRelationships) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if _, ok := u.relationships[userID]; ok {
+		return
+	}
+	u.relationships[userID] = relationships
+
+	if relationships == nil || relationships.Teams == nil || relationships.Teams.Data == nil {
+		u.missingTeams = true
+		return
+	}
+
+	teams := *relationships.Teams.Data
+	if meta := relationships.Teams.Meta; meta != nil && (meta.CountTruncated || meta.Count > len(teams)) {
+		u.missingTeams = true
+	}
+
+	for _, team := range teams {
+		teamID := strconv.Itoa(team.Id)
+		u.teamMembers[teamID] = append(u.teamMembers[teamID], userID)
+	}
+}
+
+// done records that the last page of users was listed, with the checkpoint the updated users were listed from when
+// the others were reused from the previous list.
+func (u *listedUsers) done(updatedSince time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.listed = true
+	u.updatedSince = updatedSince
+}
+
+// forget drops a user whose relationships were changed, so they are requested again. The members of the teams
+// are unknown from then on, until the users are listed again.
+func (u *listedUsers) forget(userID string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	delete(u.relationships, userID)
+	u.missingTeams = true
+}
+
+func (u *listedUsers) get(userID string) (*client.UserRelationships, bool) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	relationships, ok := u.relationships[userID]
+	return relationships, ok
+}
+
+// members returns the IDs of the members of the team, when the teams of every user are known. The members of a team
+// updated since the checkpoint of the updated users are unknown, since they can change without updating the users.
+func (u *listedUsers) members(teamID string, teamUpdatedAt time.Time) ([]string, bool) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	if !u.listed || u.missingTeams {
+		return nil, false
+	}
+
+	if !u.updatedSince.IsZero() && !teamUpdatedAt.Before(u.updatedSince) {
+		return nil, false
+	}
+
+	// The users updated since the previous list come first, so the members are sorted like the listed users.
+	memberIDs := slices.Clone(u.teamMembers[teamID])
+	slices.SortFunc(memberIDs, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b))
+	})
+
+	return memberIDs, true
+}
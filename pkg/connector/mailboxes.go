@@ -0,0 +1,180 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const mailboxOwnerEntitlementName = "owner"
+
+type mailboxBuilder struct {
+	client *client.OutreachClient
+
+	// owners keeps the ID of the user owning every listed mailbox, so Grants can be resolved without requesting each
+	// mailbox again. Mailboxes without a user are kept with 0.
+	owners   map[string]int
+	ownersMu sync.RWMutex
+}
+
+func (b *mailboxBuilder) ResourceType(_ context.Context) *v2.ResourceType {
+	return mailboxResourceType
+}
+
+func (b *mailboxBuilder) List(ctx context.Context, _ *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var (
+		mailboxResources []*v2.Resource
+		nextPageToken    string
+	)
+	outAnnotations := annotations.Annotations{}
+
+	bag, cursor, err := client.GetToken(pToken.Token, &v2.ResourceId{ResourceType: mailboxResourceType.Id})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	mailboxes, nextCursor, rateLimitData, err := b.client.ListAllMailboxes(ctx, cursor)
+	if err != nil {
+		if rateLimitData != nil {
+			outAnnotations.WithRateLimiting(rateLimitData)
+		}
+		return nil, "", outAnnotations, err
+	}
+
+	for _, mailbox := range mailboxes {
+		mailboxResource, err := parseIntoMailboxResource(*mailbox)
+		if err != nil {
+			return nil, "", outAnnotations, err
+		}
+
+		b.setOwner(mailboxResource.Id.Resource, mailbox.OwnerID())
+		mailboxResources = append(mailboxResources, mailboxResource)
+	}
+
+	if nextCursor != "" {
+		nextPageToken, err = bag.NextToken(nextCursor)
+		if err != nil {
+			return nil, "", outAnnotations, err
+		}
+	}
+
+	return mailboxResources, nextPageToken, outAnnotations, nil
+}
+
+func (b *mailboxBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	var outAnnotations annotations.Annotations
+
+	ownerOptions := []entitlement.EntitlementOption{
+		entitlement.WithGrantableTo(userResourceType),
+		entitlement.WithDisplayName(fmt.Sprintf("%s mailbox owner", resource.DisplayName)),
+		entitlement.WithDescription(fmt.Sprintf("Owner of the %s mailbox, who can send emails from it.", resource.DisplayName)),
+	}
+
+	return []*v2.Entitlement{entitlement.NewPermissionEntitlement(resource, mailboxOwnerEntitlementName, ownerOptions...)}, "", outAnnotations, nil
+}
+
+// Grants returns the owner grant of the mailbox user.
+// The owner is taken from the data cached on List, and the mailbox is only requested when it is missing from it.
+func (b *mailboxBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	outAnnotations := annotations.Annotations{}
+
+	mailboxID := resource.Id.Resource
+
+	ownerID, ok := b.getOwner(mailboxID)
+	if !ok {
+		mailbox, rateLimitData, err := b.client.GetMailboxByID(ctx, mailboxID)
+		if err != nil {
+			if rateLimitData != nil {
+				outAnnotations.WithRateLimiting(rateLimitData)
+			}
+			return nil, "", outAnnotations, err
+		}
+
+		ownerID = mailbox.OwnerID()
+	}
+
+	if ownerID == 0 {
+		return nil, "", outAnnotations, nil
+	}
+
+	ownerResource := &v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: userResourceType.Id,
+			Resource:     strconv.Itoa(ownerID),
+		},
+	}
+
+	return []*v2.Grant{grant.NewGrant(resource, mailboxOwnerEntitlementName, ownerResource)}, "", outAnnotations, nil
+}
+
+func (b *mailboxBuilder) setOwner(mailboxID string, ownerID int) {
+	b.ownersMu.Lock()
+	defer b.ownersMu.Unlock()
+
+	b.owners[mailboxID] = ownerID
+}
+
+func (b *mailboxBuilder) getOwner(mailboxID string) (int, bool) {
+	b.ownersMu.RLock()
+	defer b.ownersMu.RUnlock()
+
+	ownerID, ok := b.owners[mailboxID]
+	return ownerID, ok
+}
+
+// parseIntoMailboxResource names the mailbox after its address. Mailboxes have no trait to hold a profile, so the
+// provider and the sync status are given in the description.
+func parseIntoMailboxResource(mailbox client.Mailbox) (*v2.Resource, error) {
+	ret, err := rs.NewResource(
+		mailbox.Attributes.Email,
+		mailboxResourceType,
+		mailbox.Id,
+		rs.WithDescription(mailboxDescription(mailbox)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func mailboxDescription(mailbox client.Mailbox) string {
+	provider := mailbox.Attributes.ProviderType
+	if provider == "" {
+		provider = "unknown"
+	}
+
+	details := []string{"provider: " + provider}
+
+	switch {
+	case mailbox.Attributes.SyncDisabled:
+		details = append(details, "sync: disabled")
+	case mailbox.Attributes.SyncFinishedAt != "":
+		details = append(details, "sync: last finished at "+mailbox.Attributes.SyncFinishedAt)
+	default:
+		details = append(details, "sync: never finished")
+	}
+
+	if mailbox.Attributes.SendDisabled {
+		details = append(details, "sending disabled")
+	}
+
+	return strings.Join(details, ", ")
+}
+
+func newMailboxBuilder(c *client.OutreachClient) *mailboxBuilder {
+	return &mailboxBuilder{
+		client: c,
+		owners: make(map[string]int),
+	}
+}
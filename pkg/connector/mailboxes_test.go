@@ -0,0 +1,130 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	"github.com/conductorone/baton-outreach/pkg/outreachtest"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"google.golang.org/grpc/codes"
+)
+
+func TestMailboxBuilderList(t *testing.T) {
+	ctx := context.Background()
+	_, c := newTestServer(t, client.WithPageSize(2))
+	builder := newMailboxBuilder(c)
+
+	var (
+		got   []string
+		token string
+	)
+	descriptions := make(map[string]string)
+	for {
+		mailboxes, nextToken, _, err := builder.List(ctx, nil, &pagination.Token{Token: token})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, mailbox := range mailboxes {
+			got = append(got, mailbox.DisplayName)
+			descriptions[mailbox.DisplayName] = mailbox.Description
+		}
+
+		if nextToken == "" {
+			break
+		}
+		token = nextToken
+	}
+
+	want := []string{"ada@outreachtest.example", "grace@outreachtest.example", "sales@outreachtest.example"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected the mailboxes %v, got %v", want, got)
+	}
+
+	wantDescriptions := map[string]string{
+		"ada@outreachtest.example":   "provider: gmail, sync: last finished at 2024-02-01T00:00:00Z",
+		"grace@outreachtest.example": "provider: office365, sync: disabled",
+		"sales@outreachtest.example": "provider: gmail, sync: last finished at 2024-02-01T00:00:00Z, sending disabled",
+	}
+	for address, wantDescription := range wantDescriptions {
+		if descriptions[address] != wantDescription {
+			t.Errorf("expected the mailbox %s to be described as %q, got %q", address, wantDescription, descriptions[address])
+		}
+	}
+}
+
+func TestMailboxBuilderGrants(t *testing.T) {
+	tests := []struct {
+		name       string
+		mailboxID  string
+		listFirst  bool
+		wantOwners []string
+		wantCode   codes.Code
+		wantFetch  bool
+	}{
+		{
+			name:       "from the listed mailboxes",
+			mailboxID:  "1",
+			listFirst:  true,
+			wantOwners: []string{"1"},
+		},
+		{
+			name:       "mailbox missing from the listed mailboxes",
+			mailboxID:  "2",
+			wantOwners: []string{"2"},
+			wantFetch:  true,
+		},
+		{
+			name:      "mailbox without user",
+			mailboxID: "3",
+			listFirst: true,
+		},
+		{
+			name:      "unknown mailbox",
+			mailboxID: "42",
+			wantCode:  codes.NotFound,
+			wantFetch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t)
+			builder := newMailboxBuilder(c)
+
+			if tt.listFirst {
+				_, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			grants, _, _, err := builder.Grants(ctx, resourceOf(mailboxResourceType, tt.mailboxID), &pagination.Token{})
+			checkCode(t, err, tt.wantCode)
+
+			fetched := slices.ContainsFunc(server.Requests(), func(r outreachtest.Request) bool {
+				return r.Method == http.MethodGet && r.Path == "/mailboxes/"+tt.mailboxID
+			})
+			if fetched != tt.wantFetch {
+				t.Errorf("expected the mailbox to be requested: %t, got %t", tt.wantFetch, fetched)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if got := principalIDs(grants); !slices.Equal(got, tt.wantOwners) {
+				t.Errorf("expected the owners %v, got %v", tt.wantOwners, got)
+			}
+			for _, g := range grants {
+				if g.Entitlement.Id != entitlementOf(resourceOf(mailboxResourceType, tt.mailboxID), mailboxOwnerEntitlementName).Id {
+					t.Errorf("expected an owner grant, got %s", g.Entitlement.Id)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package connector
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultProfileSpecialID is the special ID of the 'Default' profile, a system-provided profile whose ID and name
+// differ between organizations.
+const defaultProfileSpecialID = "default"
+
+// profileFallback finds the profile a user is moved to when one of its profiles is revoked, since every user must have
+// a profile. That's the profile named in the configuration, or else the 'Default' profile, found once and reused.
+// When restorePrevious is set, the profile a user had before a grant made by the connector is restored instead.
+type profileFallback struct {
+	client          *client.OutreachClient
+	name            string
+	restorePrevious bool
+
+	mu        sync.Mutex
+	profileID int
+	// previous holds the profile each user had before its last profile grant, by user ID.
+	previous map[string]int
+}
+
+func newProfileFallback(c *client.OutreachClient, name string, restorePrevious bool) *profileFallback {
+	return &profileFallback{
+		client:          c,
+		name:            name,
+		restorePrevious: restorePrevious,
+		previous:        make(map[string]int),
+	}
+}
+
+// record keeps the profile the user had before it was granted another one, so a later revoke can restore it.
+// Only the grants made since the connector started are known.
+func (f *profileFallback) record(userID string, previousProfileID int) {
+	if !f.restorePrevious || previousProfileID == 0 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.previous[userID] = previousProfileID
+}
+
+// target returns the profile the user is moved to when the revoked profile is taken away from it.
+// The fallback profile itself can't be revoked, since the user would be left with it.
+func (f *profileFallback) target(ctx context.Context, userID string, revokedProfileID int) (int, annotations.Annotations, error) {
+	if f.restorePrevious {
+		f.mu.Lock()
+		previousProfileID, ok := f.previous[userID]
+		delete(f.previous, userID)
+		f.mu.Unlock()
+
+		if ok && previousProfileID != revokedProfileID {
+			return previousProfileID, annotations.Annotations{}, nil
+		}
+	}
+
+	profileID, outAnnotations, err := f.fallbackProfileID(ctx)
+	if err != nil {
+		return 0, outAnnotations, err
+	}
+
+	if profileID == revokedProfileID {
+		return 0, outAnnotations, status.Errorf(
+			codes.FailedPrecondition,
+			"outreach: the profile {%d} is the fallback profile users are moved to, so it can't be revoked from the user {%s}, grant another profile to the user instead",
+			revokedProfileID,
+			userID,
+		)
+	}
+
+	return profileID, outAnnotations, nil
+}
+
+// fallbackProfileID returns the ID of the fallback profile, listing the profiles the first time it is needed.
+func (f *profileFallback) fallbackProfileID(ctx context.Context) (int, annotations.Annotations, error) {
+	outAnnotations := annotations.Annotations{}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.profileID != 0 {
+		return f.profileID, outAnnotations, nil
+	}
+
+	var cursor string
+	for {
+		profiles, nextCursor, rateLimitData, err := f.client.ListAllProfiles(ctx, cursor, client.UpdatedAtRange{})
+		if err != nil {
+			if rateLimitData != nil {
+				outAnnotations.WithRateLimiting(rateLimitData)
+			}
+			return 0, outAnnotations, err
+		}
+
+		for _, profile := range profiles {
+			if f.matches(profile) {
+				f.profileID = profile.Id
+				return f.profileID, outAnnotations, nil
+			}
+		}
+
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	if f.name != "" {
+		return 0, outAnnotations, status.Errorf(codes.FailedPrecondition, "outreach: the fallback profile %s was not found", strconv.Quote(f.name))
+	}
+
+	return 0, outAnnotations, status.Errorf(codes.FailedPrecondition, "outreach: no profile has the special ID %s to fall back to", strconv.Quote(defaultProfileSpecialID))
+}
+
+func (f *profileFallback) matches(profile *client.Profile) bool {
+	if f.name != "" {
+		return strings.EqualFold(strings.TrimSpace(profile.Attributes.Name), strings.TrimSpace(f.name))
+	}
+
+	return profile.Attributes.SpecialId == defaultProfileSpecialID
+}
@@ -2,7 +2,9 @@ package connector
 
 import (
 	"context"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/conductorone/baton-outreach/pkg/connector/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
@@ -10,14 +12,18 @@ import (
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
 	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-// defaultProfileID is the ID for the 'Default' profile, a system-provided profile.
-const defaultProfileID = 2
 const profilePermissionName = "assigned"
 
 type profileBuilder struct {
-	client *client.OutreachClient
+	client   *client.OutreachClient
+	profiles *deltaList[client.Profile]
+	fallback *profileFallback
 }
 
 func (b *profileBuilder) ResourceType(_ context.Context) *v2.ResourceType {
@@ -25,18 +31,10 @@ func (b *profileBuilder) ResourceType(_ context.Context) *v2.ResourceType {
 }
 
 func (b *profileBuilder) List(ctx context.Context, _ *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
-	var (
-		profileResources []*v2.Resource
-		nextPageToken    string
-	)
+	var profileResources []*v2.Resource
 	outAnnotations := annotations.Annotations{}
 
-	bag, nextPage, err := client.GetToken(pToken.Token, &v2.ResourceId{ResourceType: profileResourceType.Id})
-	if err != nil {
-		return nil, "", nil, err
-	}
-
-	profiles, nextPageLink, rateLimitData, err := b.client.ListAllProfiles(ctx, nextPage)
+	profiles, nextPageToken, rateLimitData, err := b.profiles.page(ctx, pToken.Token)
 	if err != nil {
 		if rateLimitData != nil {
 			outAnnotations.WithRateLimiting(rateLimitData)
@@ -53,23 +51,18 @@ func (b *profileBuilder) List(ctx context.Context, _ *v2.ResourceId, pToken *pag
 		profileResources = append(profileResources, profileResource)
 	}
 
-	if nextPageLink != "" {
-		nextPageToken, err = bag.NextToken(nextPageLink)
-		if err != nil {
-			return nil, "", outAnnotations, err
-		}
-	}
-
 	return profileResources, nextPageToken, outAnnotations, nil
 }
 
 func (b *profileBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	var outAnnotations annotations.Annotations
 
+	// The SDK has no annotation for mutually exclusive entitlements, so the description tells that every profile
+	// replaces the others.
 	assigmentOptions := []entitlement.EntitlementOption{
 		entitlement.WithGrantableTo(userResourceType),
 		entitlement.WithDisplayName(resource.DisplayName),
-		entitlement.WithDescription(resource.DisplayName),
+		entitlement.WithDescription(fmt.Sprintf("%s profile. Users have exactly one profile, so granting it replaces their current profile.", resource.DisplayName)),
 	}
 
 	return []*v2.Entitlement{entitlement.NewPermissionEntitlement(resource, profilePermissionName, assigmentOptions...)}, "", outAnnotations, nil
@@ -80,30 +73,106 @@ func (b *profileBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination
 	return nil, "", nil, nil
 }
 
+// Grant moves the user to the profile. Outreach users have exactly one profile, so it replaces the current one.
 func (b *profileBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
-	outAnnotations := annotations.Annotations{}
+	ctx = client.ContextWithProvisioning(ctx)
 	profileID, err := strconv.Atoi(entitlement.Resource.Id.Resource)
 	if err != nil {
-		return outAnnotations, err
+		return annotations.Annotations{}, err
 	}
 	userID := principal.Id.Resource
 
-	rateLimitData, err := b.client.UpdateUserProfile(ctx, userID, profileID)
+	currentProfileID, outAnnotations, err := b.currentProfile(ctx, userID)
+	if err != nil {
+		return outAnnotations, err
+	}
+
+	if currentProfileID == profileID {
+		outAnnotations.Update(&v2.GrantAlreadyExists{})
+		return outAnnotations, nil
+	}
+
+	updateAnnotations, err := b.updateProfile(ctx, userID, profileID)
+	outAnnotations.Merge(updateAnnotations...)
 	if err != nil {
-		if rateLimitData != nil {
-			outAnnotations.WithRateLimiting(rateLimitData)
-		}
 		return outAnnotations, err
 	}
 
+	b.fallback.record(userID, currentProfileID)
+
 	return outAnnotations, nil
 }
 
+// Revoke moves the user to the fallback profile, or back to the profile it had before the grant when it is known,
+// since every user must have a profile. Users that were already moved to another profile are left as they are.
+// The fallback profile itself can't be revoked, another profile has to be granted instead.
 func (b *profileBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
-	outAnnotations := annotations.Annotations{}
-	profileID := defaultProfileID
+	ctx = client.ContextWithProvisioning(ctx)
+	revokedProfileID, err := strconv.Atoi(grant.Entitlement.Resource.Id.Resource)
+	if err != nil {
+		return annotations.Annotations{}, err
+	}
 	userID := grant.Principal.Id.Resource
 
+	currentProfileID, outAnnotations, err := b.currentProfile(ctx, userID)
+	if err != nil {
+		return outAnnotations, err
+	}
+
+	if currentProfileID != revokedProfileID {
+		outAnnotations.Update(&v2.GrantAlreadyRevoked{})
+		return outAnnotations, nil
+	}
+
+	profileID, targetAnnotations, err := b.fallback.target(ctx, userID, revokedProfileID)
+	outAnnotations.Merge(targetAnnotations...)
+	if err != nil {
+		return outAnnotations, err
+	}
+
+	updateAnnotations, err := b.updateProfile(ctx, userID, profileID)
+	outAnnotations.Merge(updateAnnotations...)
+
+	return outAnnotations, err
+}
+
+// currentProfile returns the ID of the profile the user has, or 0 when it has none.
+func (b *profileBuilder) currentProfile(ctx context.Context, userID string) (int, annotations.Annotations, error) {
+	outAnnotations := annotations.Annotations{}
+
+	user, rateLimitData, err := b.client.GetUserByID(ctx, userID)
+	if err != nil {
+		if rateLimitData != nil {
+			outAnnotations.WithRateLimiting(rateLimitData)
+		}
+		return 0, outAnnotations, err
+	}
+
+	if user.Relationships == nil || user.Relationships.Profile == nil || user.Relationships.Profile.Data == nil {
+		return 0, outAnnotations, nil
+	}
+
+	return user.Relationships.Profile.Data.Id, outAnnotations, nil
+}
+
+// updateProfile changes the profile of the user, within the next batch when batching is enabled. The user is only
+// updated on its own when the batched update was overwritten.
+func (b *profileBuilder) updateProfile(ctx context.Context, userID string, profileID int) (annotations.Annotations, error) {
+	outAnnotations := annotations.Annotations{}
+
+	if b.client.Batching() {
+		err := b.client.BatchUpdateUserProfile(ctx, userID, profileID)
+		if status.Code(err) != codes.Aborted {
+			return outAnnotations, err
+		}
+
+		ctxzap.Extract(ctx).Warn(
+			"the batched update of the user profile was overwritten, updating the user on its own",
+			zap.String("user_id", userID),
+			zap.Int("profile_id", profileID),
+		)
+	}
+
 	rateLimitData, err := b.client.UpdateUserProfile(ctx, userID, profileID)
 	if err != nil {
 		if rateLimitData != nil {
@@ -143,8 +212,12 @@ func parseIntoProfileResource(prof client.Profile) (*v2.Resource, error) {
 	return ret, nil
 }
 
-func newProfileBuilder(c *client.OutreachClient) *profileBuilder {
+func newProfileBuilder(c *client.OutreachClient, fullSyncInterval time.Duration, fallbackProfile string, restorePreviousProfile bool) *profileBuilder {
 	return &profileBuilder{
 		client: c,
+		profiles: newDeltaList(profileResourceType.Id, fullSyncInterval, c.ListAllProfiles, func(profile *client.Profile) int {
+			return profile.Id
+		}),
+		fallback: newProfileFallback(c, fallbackProfile, restorePreviousProfile),
 	}
 }
@@ -0,0 +1,301 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"google.golang.org/grpc/codes"
+)
+
+func TestProfileBuilderList(t *testing.T) {
+	ctx := context.Background()
+	_, c := newTestServer(t)
+	builder := newProfileBuilder(c, 0, "", false)
+
+	profiles, nextToken, _, err := builder.List(ctx, nil, &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resourceIDs(profiles); !slices.Equal(got, []string{"1", "2", "3"}) || nextToken != "" {
+		t.Fatalf("unexpected profiles %v", got)
+	}
+
+	grants, _, _, err := builder.Grants(ctx, profiles[0], &pagination.Token{})
+	if err != nil || len(grants) != 0 {
+		t.Errorf("expected the profile grants to be found on the users, got %v (error: %v)", grants, err)
+	}
+}
+
+func TestProfileBuilderGrant(t *testing.T) {
+	tests := []struct {
+		name          string
+		profileID     string
+		userID        string
+		wantProfile   int
+		wantCode      codes.Code
+		alreadyExists bool
+	}{
+		{
+			name:        "other profile",
+			profileID:   "3",
+			userID:      "1",
+			wantProfile: 3,
+		},
+		{
+			name:          "current profile",
+			profileID:     "1",
+			userID:        "1",
+			wantProfile:   1,
+			alreadyExists: true,
+		},
+		{
+			name:        "unknown profile",
+			profileID:   "42",
+			userID:      "1",
+			wantProfile: 1,
+			wantCode:    codes.InvalidArgument,
+		},
+		{
+			name:      "unknown user",
+			profileID: "3",
+			userID:    "42",
+			wantCode:  codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t)
+			profile := resourceOf(profileResourceType, tt.profileID)
+
+			annos, err := newProfileBuilder(c, 0, "", false).Grant(ctx, resourceOf(userResourceType, tt.userID), entitlementOf(profile, profilePermissionName))
+			checkCode(t, err, tt.wantCode)
+
+			if got := annos.Contains(&v2.GrantAlreadyExists{}); got != tt.alreadyExists {
+				t.Errorf("expected GrantAlreadyExists to be %t, got %t", tt.alreadyExists, got)
+			}
+
+			if tt.wantProfile != 0 {
+				checkRelationship(t, server, "user", tt.userID, userProfile, tt.wantProfile)
+			}
+		})
+	}
+}
+
+func TestProfileBuilderRevoke(t *testing.T) {
+	tests := []struct {
+		name            string
+		fallbackProfile string
+		restorePrevious bool
+		seed            []client.ResourceObject
+		grantedProfile  string
+		userID          string
+		revokedProfile  string
+		wantProfile     int
+		wantCode        codes.Code
+		alreadyRevoked  bool
+	}{
+		{
+			name:           "default profile",
+			userID:         "1",
+			revokedProfile: "1",
+			wantProfile:    2,
+		},
+		{
+			name: "default profile with another ID",
+			seed: []client.ResourceObject{
+				{Id: 2, Type: "profile", Attributes: []byte(`{"name":"Legacy","specialId":null}`)},
+				{Id: 7, Type: "profile", Attributes: []byte(`{"name":"Standard","specialId":"default"}`)},
+			},
+			userID:         "1",
+			revokedProfile: "1",
+			wantProfile:    7,
+		},
+		{
+			name:           "fallback profile",
+			userID:         "2",
+			revokedProfile: "2",
+			wantProfile:    2,
+			wantCode:       codes.FailedPrecondition,
+		},
+		{
+			name:            "configured fallback profile",
+			fallbackProfile: "sales rep",
+			userID:          "3",
+			revokedProfile:  "3",
+			wantProfile:     3,
+			wantCode:        codes.FailedPrecondition,
+		},
+		{
+			name:           "already moved to another profile",
+			userID:         "1",
+			revokedProfile: "3",
+			wantProfile:    1,
+			alreadyRevoked: true,
+		},
+		{
+			name:            "configured fallback",
+			fallbackProfile: "sales rep",
+			userID:          "1",
+			revokedProfile:  "1",
+			wantProfile:     3,
+		},
+		{
+			name:            "unknown configured fallback",
+			fallbackProfile: "Missing",
+			userID:          "1",
+			revokedProfile:  "1",
+			wantProfile:     1,
+			wantCode:        codes.FailedPrecondition,
+		},
+		{
+			name:            "previous profile restored",
+			restorePrevious: true,
+			grantedProfile:  "3",
+			userID:          "1",
+			revokedProfile:  "3",
+			wantProfile:     1,
+		},
+		{
+			name:            "previous profile unknown",
+			restorePrevious: true,
+			userID:          "3",
+			revokedProfile:  "3",
+			wantProfile:     2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t)
+			if err := server.Seed(tt.seed...); err != nil {
+				t.Fatal(err)
+			}
+			builder := newProfileBuilder(c, 0, tt.fallbackProfile, tt.restorePrevious)
+			user := resourceOf(userResourceType, tt.userID)
+
+			if tt.grantedProfile != "" {
+				granted := resourceOf(profileResourceType, tt.grantedProfile)
+				if _, err := builder.Grant(ctx, user, entitlementOf(granted, profilePermissionName)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			revoked := resourceOf(profileResourceType, tt.revokedProfile)
+			annos, err := builder.Revoke(ctx, &v2.Grant{
+				Entitlement: entitlementOf(revoked, profilePermissionName),
+				Principal:   user,
+			})
+			checkCode(t, err, tt.wantCode)
+
+			if got := annos.Contains(&v2.GrantAlreadyRevoked{}); got != tt.alreadyRevoked {
+				t.Errorf("expected GrantAlreadyRevoked to be %t, got %t", tt.alreadyRevoked, got)
+			}
+
+			checkRelationship(t, server, "user", tt.userID, userProfile, tt.wantProfile)
+		})
+	}
+}
+
+func TestProfileBuilderBatchedGrants(t *testing.T) {
+	tests := []struct {
+		name            string
+		failedUser      string
+		overwrittenUser string
+		// wantUpdates is the number of users updated on their own, once their batched update was overwritten.
+		wantUpdates  int
+		wantProfiles map[string]int
+		wantCodes    map[string]codes.Code
+	}{
+		{
+			name:         "coalesced",
+			wantProfiles: map[string]int{"1": 3, "2": 3, "3": 2},
+			wantCodes:    map[string]codes.Code{"1": codes.OK, "2": codes.OK, "3": codes.OK},
+		},
+		{
+			name:         "failed item",
+			failedUser:   "1",
+			wantProfiles: map[string]int{"1": 1, "2": 3, "3": 2},
+			wantCodes:    map[string]codes.Code{"1": codes.Unknown, "2": codes.OK, "3": codes.OK},
+		},
+		{
+			name:            "overwritten",
+			overwrittenUser: "1",
+			wantUpdates:     1,
+			wantProfiles:    map[string]int{"1": 3, "2": 3, "3": 2},
+			wantCodes:       map[string]codes.Code{"1": codes.OK, "2": codes.OK, "3": codes.OK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t, client.WithBatching(client.BatchOptions{
+				Window:       50 * time.Millisecond,
+				PollInterval: 10 * time.Millisecond,
+			}))
+			if tt.failedUser != "" {
+				server.Fail(http.MethodPatch, "/users/"+tt.failedUser, http.StatusUnprocessableEntity, 1)
+			}
+			if tt.overwrittenUser != "" {
+				server.DropUpdates("/users/"+tt.overwrittenUser, 1)
+			}
+			builder := newProfileBuilder(c, 0, "", false)
+			profile := resourceOf(profileResourceType, "3")
+
+			var (
+				wg   sync.WaitGroup
+				mu   sync.Mutex
+				errs = make(map[string]error)
+			)
+			for _, userID := range []string{"1", "2", "3"} {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					var err error
+					if userID == "3" {
+						_, err = builder.Revoke(ctx, &v2.Grant{
+							Entitlement: entitlementOf(profile, profilePermissionName),
+							Principal:   resourceOf(userResourceType, userID),
+						})
+					} else {
+						_, err = builder.Grant(ctx, resourceOf(userResourceType, userID), entitlementOf(profile, profilePermissionName))
+					}
+
+					mu.Lock()
+					errs[userID] = err
+					mu.Unlock()
+				}()
+			}
+			wg.Wait()
+
+			for userID, wantCode := range tt.wantCodes {
+				checkCode(t, errs[userID], wantCode)
+				checkRelationship(t, server, "user", userID, userProfile, tt.wantProfiles[userID])
+			}
+
+			batches, updates := 0, 0
+			for _, request := range server.Requests() {
+				switch {
+				case request.Method == http.MethodPost && request.Path == "/batches":
+					batches++
+				case request.Method == http.MethodPatch:
+					updates++
+				}
+			}
+			if batches != 1 || updates != tt.wantUpdates {
+				t.Errorf("expected the profiles to be changed by a single batch and %d updates, got %d batches and %d updates", tt.wantUpdates, batches, updates)
+			}
+		})
+	}
+}
@@ -22,3 +22,22 @@ var profileResourceType = &v2.ResourceType{
 	DisplayName: "Profile",
 	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_ROLE},
 }
+
+// The role resource type is for the roles of the organization hierarchy. Child roles are synced under their parent.
+var roleResourceType = &v2.ResourceType{
+	Id:          "role",
+	DisplayName: "Role",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_ROLE},
+}
+
+// The mailbox resource type is for the email accounts connected to Outreach, which sequences send from.
+var mailboxResourceType = &v2.ResourceType{
+	Id:          "mailbox",
+	DisplayName: "Mailbox",
+}
+
+// The sequence resource type is for the sequences of the organization, owned by a user and shared with teams.
+var sequenceResourceType = &v2.ResourceType{
+	Id:          "sequence",
+	DisplayName: "Sequence",
+}
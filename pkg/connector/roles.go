@@ -0,0 +1,212 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const rolePermissionName = "assigned"
+
+type roleBuilder struct {
+	client *client.OutreachClient
+}
+
+func (b *roleBuilder) ResourceType(_ context.Context) *v2.ResourceType {
+	return roleResourceType
+}
+
+// List lists the roles at the top of the hierarchy, or the direct children of the parent role. Every role is annotated
+// with the role child type, so its own children are listed under it.
+func (b *roleBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var (
+		roleResources []*v2.Resource
+		nextPageToken string
+		parentRoleID  string
+	)
+	outAnnotations := annotations.Annotations{}
+
+	if parentResourceID != nil {
+		parentRoleID = parentResourceID.Resource
+	}
+
+	bag, cursor, err := client.GetToken(pToken.Token, &v2.ResourceId{ResourceType: roleResourceType.Id, Resource: parentRoleID})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	roles, nextCursor, rateLimitData, err := b.client.ListAllRoles(ctx, cursor, parentRoleID)
+	if err != nil {
+		if rateLimitData != nil {
+			outAnnotations.WithRateLimiting(rateLimitData)
+		}
+		return nil, "", outAnnotations, err
+	}
+
+	for _, role := range roles {
+		// Outreach can't filter the roles without a parent, so the child roles are skipped here.
+		if parentResourceID == nil && role.ParentRoleID() != 0 {
+			continue
+		}
+
+		roleResource, err := parseIntoRoleResource(*role, parentResourceID)
+		if err != nil {
+			return nil, "", outAnnotations, err
+		}
+
+		roleResources = append(roleResources, roleResource)
+	}
+
+	if nextCursor != "" {
+		nextPageToken, err = bag.NextToken(nextCursor)
+		if err != nil {
+			return nil, "", outAnnotations, err
+		}
+	}
+
+	return roleResources, nextPageToken, outAnnotations, nil
+}
+
+func (b *roleBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	var outAnnotations annotations.Annotations
+
+	assigmentOptions := []entitlement.EntitlementOption{
+		entitlement.WithGrantableTo(userResourceType),
+		entitlement.WithDisplayName(resource.DisplayName),
+		entitlement.WithDescription(fmt.Sprintf("%s role. Users have at most one role, so granting it replaces their current role.", resource.DisplayName)),
+	}
+
+	return []*v2.Entitlement{entitlement.NewPermissionEntitlement(resource, rolePermissionName, assigmentOptions...)}, "", outAnnotations, nil
+}
+
+// Grants function gets implemented on the users resource, since the users records have that data.
+func (b *roleBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grant sets the role of the user, replacing its current one.
+func (b *roleBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	ctx = client.ContextWithProvisioning(ctx)
+	roleID, err := strconv.Atoi(entitlement.Resource.Id.Resource)
+	if err != nil {
+		return annotations.Annotations{}, err
+	}
+	userID := principal.Id.Resource
+
+	currentRoleID, outAnnotations, err := b.currentRole(ctx, userID)
+	if err != nil {
+		return outAnnotations, err
+	}
+
+	if currentRoleID == roleID {
+		outAnnotations.Update(&v2.GrantAlreadyExists{})
+		return outAnnotations, nil
+	}
+
+	rateLimitData, err := b.client.UpdateUserRole(ctx, userID, &roleID)
+	if err != nil {
+		if rateLimitData != nil {
+			outAnnotations.WithRateLimiting(rateLimitData)
+		}
+		return outAnnotations, err
+	}
+
+	return outAnnotations, nil
+}
+
+// Revoke removes the role of the user, unless the user already has another role.
+func (b *roleBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	ctx = client.ContextWithProvisioning(ctx)
+	roleID, err := strconv.Atoi(grant.Entitlement.Resource.Id.Resource)
+	if err != nil {
+		return annotations.Annotations{}, err
+	}
+	userID := grant.Principal.Id.Resource
+
+	currentRoleID, outAnnotations, err := b.currentRole(ctx, userID)
+	if err != nil {
+		return outAnnotations, err
+	}
+
+	if currentRoleID != roleID {
+		outAnnotations.Update(&v2.GrantAlreadyRevoked{})
+		return outAnnotations, nil
+	}
+
+	rateLimitData, err := b.client.UpdateUserRole(ctx, userID, nil)
+	if err != nil {
+		if rateLimitData != nil {
+			outAnnotations.WithRateLimiting(rateLimitData)
+		}
+		return outAnnotations, err
+	}
+
+	return outAnnotations, nil
+}
+
+// currentRole returns the ID of the role the user has, or 0 when it has none.
+func (b *roleBuilder) currentRole(ctx context.Context, userID string) (int, annotations.Annotations, error) {
+	outAnnotations := annotations.Annotations{}
+
+	user, rateLimitData, err := b.client.GetUserByID(ctx, userID)
+	if err != nil {
+		if rateLimitData != nil {
+			outAnnotations.WithRateLimiting(rateLimitData)
+		}
+		return 0, outAnnotations, err
+	}
+
+	if user.Relationships == nil || user.Relationships.Role == nil || user.Relationships.Role.Data == nil {
+		return 0, outAnnotations, nil
+	}
+
+	return user.Relationships.Role.Data.Id, outAnnotations, nil
+}
+
+func parseIntoRoleResource(role client.Role, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"name":       role.Attributes.Name,
+		"created_at": role.Attributes.CreatedAt,
+		"updated_at": role.Attributes.UpdatedAt,
+	}
+	if parentRoleID := role.ParentRoleID(); parentRoleID != 0 {
+		profile["parent_role_id"] = parentRoleID
+	}
+
+	roleTraits := []rs.RoleTraitOption{
+		rs.WithRoleProfile(profile),
+	}
+
+	resourceOptions := []rs.ResourceOption{
+		rs.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: roleResourceType.Id}),
+	}
+	if parentResourceID != nil {
+		resourceOptions = append(resourceOptions, rs.WithParentResourceID(parentResourceID))
+	}
+
+	ret, err := rs.NewRoleResource(
+		role.Attributes.Name,
+		roleResourceType,
+		role.Id,
+		roleTraits,
+		resourceOptions...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func newRoleBuilder(c *client.OutreachClient) *roleBuilder {
+	return &roleBuilder{
+		client: c,
+	}
+}
@@ -0,0 +1,194 @@
+package connector
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestRoleBuilderList(t *testing.T) {
+	tests := []struct {
+		name       string
+		parentRole string
+		pageSize   int
+		wantRoles  []string
+	}{
+		{
+			name:      "top of the hierarchy",
+			wantRoles: []string{"1"},
+		},
+		{
+			name:       "children of a role",
+			parentRole: "1",
+			wantRoles:  []string{"2", "4"},
+		},
+		{
+			name:       "children of a role over several pages",
+			parentRole: "1",
+			pageSize:   1,
+			wantRoles:  []string{"2", "4"},
+		},
+		{
+			name:       "role without children",
+			parentRole: "3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			_, c := newTestServer(t, client.WithPageSize(tt.pageSize))
+			builder := newRoleBuilder(c)
+
+			var parentResourceID *v2.ResourceId
+			if tt.parentRole != "" {
+				parentResourceID = resourceOf(roleResourceType, tt.parentRole).Id
+			}
+
+			var (
+				got   []string
+				token string
+			)
+			for {
+				roles, nextToken, _, err := builder.List(ctx, parentResourceID, &pagination.Token{Token: token})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				for _, role := range roles {
+					if !slices.ContainsFunc(role.Annotations, func(a *anypb.Any) bool {
+						return a.MessageIs(&v2.ChildResourceType{})
+					}) {
+						t.Errorf("expected the role %s to have the role child type", role.Id.Resource)
+					}
+					if !proto.Equal(role.ParentResourceId, parentResourceID) {
+						t.Errorf("expected the role %s to have the parent %v, got %v", role.Id.Resource, parentResourceID, role.ParentResourceId)
+					}
+				}
+				got = append(got, resourceIDs(roles)...)
+
+				if nextToken == "" {
+					break
+				}
+				token = nextToken
+			}
+
+			if !slices.Equal(got, tt.wantRoles) {
+				t.Errorf("expected the roles %v, got %v", tt.wantRoles, got)
+			}
+		})
+	}
+}
+
+func TestRoleBuilderGrant(t *testing.T) {
+	tests := []struct {
+		name          string
+		roleID        string
+		userID        string
+		wantRole      int
+		wantCode      codes.Code
+		alreadyExists bool
+	}{
+		{
+			name:     "user without role",
+			roleID:   "3",
+			userID:   "3",
+			wantRole: 3,
+		},
+		{
+			name:     "other role",
+			roleID:   "2",
+			userID:   "1",
+			wantRole: 2,
+		},
+		{
+			name:          "current role",
+			roleID:        "4",
+			userID:        "1",
+			wantRole:      4,
+			alreadyExists: true,
+		},
+		{
+			name:     "unknown role",
+			roleID:   "42",
+			userID:   "1",
+			wantRole: 4,
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t)
+			role := resourceOf(roleResourceType, tt.roleID)
+
+			annos, err := newRoleBuilder(c).Grant(ctx, resourceOf(userResourceType, tt.userID), entitlementOf(role, rolePermissionName))
+			checkCode(t, err, tt.wantCode)
+
+			if got := annos.Contains(&v2.GrantAlreadyExists{}); got != tt.alreadyExists {
+				t.Errorf("expected GrantAlreadyExists to be %t, got %t", tt.alreadyExists, got)
+			}
+
+			checkRelationship(t, server, "user", tt.userID, userRole, tt.wantRole)
+		})
+	}
+}
+
+func TestRoleBuilderRevoke(t *testing.T) {
+	tests := []struct {
+		name           string
+		roleID         string
+		userID         string
+		wantRole       int
+		alreadyRevoked bool
+	}{
+		{
+			name:   "current role",
+			roleID: "4",
+			userID: "1",
+		},
+		{
+			name:           "other role",
+			roleID:         "2",
+			userID:         "1",
+			wantRole:       4,
+			alreadyRevoked: true,
+		},
+		{
+			name:           "user without role",
+			roleID:         "2",
+			userID:         "3",
+			alreadyRevoked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t)
+			role := resourceOf(roleResourceType, tt.roleID)
+
+			annos, err := newRoleBuilder(c).Revoke(ctx, &v2.Grant{
+				Entitlement: entitlementOf(role, rolePermissionName),
+				Principal:   resourceOf(userResourceType, tt.userID),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := annos.Contains(&v2.GrantAlreadyRevoked{}); got != tt.alreadyRevoked {
+				t.Errorf("expected GrantAlreadyRevoked to be %t, got %t", tt.alreadyRevoked, got)
+			}
+
+			checkRelationship(t, server, "user", tt.userID, userRole, tt.wantRole)
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	sequenceOwnerEntitlementName  = "owner"
+	sequenceSharedEntitlementName = "shared"
+)
+
+type sequenceBuilder struct {
+	client *client.OutreachClient
+
+	// relationships keeps the relationship data of every listed sequence, so Grants can be resolved
+	// without requesting each sequence again.
+	relationships   map[string]*client.SequenceRelationships
+	relationshipsMu sync.RWMutex
+}
+
+func (b *sequenceBuilder) ResourceType(_ context.Context) *v2.ResourceType {
+	return sequenceResourceType
+}
+
+func (b *sequenceBuilder) List(ctx context.Context, _ *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var (
+		sequenceResources []*v2.Resource
+		nextPageToken     string
+	)
+	outAnnotations := annotations.Annotations{}
+
+	bag, cursor, err := client.GetToken(pToken.Token, &v2.ResourceId{ResourceType: sequenceResourceType.Id})
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	sequences, nextCursor, rateLimitData, err := b.client.ListAllSequences(ctx, cursor)
+	if err != nil {
+		if rateLimitData != nil {
+			outAnnotations.WithRateLimiting(rateLimitData)
+		}
+		return nil, "", outAnnotations, err
+	}
+
+	for _, sequence := range sequences {
+		sequenceResource, err := parseIntoSequenceResource(*sequence)
+		if err != nil {
+			return nil, "", outAnnotations, err
+		}
+
+		b.setRelationships(sequenceResource.Id.Resource, sequence.Relationships)
+		sequenceResources = append(sequenceResources, sequenceResource)
+	}
+
+	if nextCursor != "" {
+		nextPageToken, err = bag.NextToken(nextCursor)
+		if err != nil {
+			return nil, "", outAnnotations, err
+		}
+	}
+
+	return sequenceResources, nextPageToken, outAnnotations, nil
+}
+
+func (b *sequenceBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	var outAnnotations annotations.Annotations
+
+	ownerOptions := []entitlement.EntitlementOption{
+		entitlement.WithGrantableTo(userResourceType),
+		entitlement.WithDisplayName(fmt.Sprintf("%s sequence owner", resource.DisplayName)),
+		entitlement.WithDescription(fmt.Sprintf("Owner of the %s sequence. A sequence has one owner, so granting it transfers the ownership.", resource.DisplayName)),
+	}
+
+	// The entitlement is granted to the teams the sequence is shared with. Sharing can't be provisioned, so Grant refuses it.
+	sharedOptions := []entitlement.EntitlementOption{
+		entitlement.WithGrantableTo(teamResourceType),
+		entitlement.WithDisplayName(fmt.Sprintf("%s sequence shared with", resource.DisplayName)),
+		entitlement.WithDescription(fmt.Sprintf(
+			"Teams the %s sequence is shared with. Sharing with the whole organization is given by the share type of the sequence, "+
+				"and sharing is managed in Outreach.",
+			resource.DisplayName,
+		)),
+	}
+
+	return []*v2.Entitlement{
+		entitlement.NewPermissionEntitlement(resource, sequenceOwnerEntitlementName, ownerOptions...),
+		entitlement.NewPermissionEntitlement(resource, sequenceSharedEntitlementName, sharedOptions...),
+	}, "", outAnnotations, nil
+}
+
+// Grants returns the owner grant of the sequence, and a shared grant for each team it is shared with, expanded to the
+// members of the team.
+// The relationships are taken from the data cached on List, and the sequence is only requested when it is missing from it.
+func (b *sequenceBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	var grantResources []*v2.Grant
+	outAnnotations := annotations.Annotations{}
+
+	sequenceID := resource.Id.Resource
+
+	relationships, ok := b.getRelationships(sequenceID)
+	if !ok {
+		sequence, rateLimitData, err := b.client.GetSequenceByID(ctx, sequenceID)
+		if err != nil {
+			if rateLimitData != nil {
+				outAnnotations.WithRateLimiting(rateLimitData)
+			}
+			return nil, "", outAnnotations, err
+		}
+
+		relationships = sequence.Relationships
+	}
+
+	sequence := client.Sequence{Relationships: relationships}
+
+	if ownerID := sequence.OwnerID(); ownerID != 0 {
+		ownerResource := &v2.Resource{
+			Id: &v2.ResourceId{
+				ResourceType: userResourceType.Id,
+				Resource:     strconv.Itoa(ownerID),
+			},
+		}
+
+		grantResources = append(grantResources, grant.NewGrant(resource, sequenceOwnerEntitlementName, ownerResource))
+	}
+
+	for _, teamID := range sequence.TeamIDs() {
+		teamResource := &v2.Resource{
+			Id: &v2.ResourceId{
+				ResourceType: teamResourceType.Id,
+				Resource:     strconv.Itoa(teamID),
+			},
+		}
+
+		grantResources = append(grantResources, grant.NewGrant(
+			resource,
+			sequenceSharedEntitlementName,
+			teamResource,
+			grant.WithAnnotation(&v2.GrantExpandable{
+				EntitlementIds: []string{entitlement.NewEntitlementID(teamResource, teamPermissionName)},
+			}),
+		))
+	}
+
+	return grantResources, "", outAnnotations, nil
+}
+
+// Grant transfers the ownership of the sequence to the user. Sharing the sequence with teams can't be provisioned.
+func (b *sequenceBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	ctx = client.ContextWithProvisioning(ctx)
+
+	if entitlement.Id != sequenceOwnerEntitlementID(entitlement.Resource) {
+		return annotations.Annotations{}, status.Errorf(codes.Unimplemented, "outreach: only the ownership of a sequence can be granted, got %s", entitlement.Id)
+	}
+
+	sequenceID := entitlement.Resource.Id.Resource
+	userID, err := strconv.Atoi(principal.Id.Resource)
+	if err != nil {
+		return annotations.Annotations{}, err
+	}
+
+	currentOwnerID, outAnnotations, err := b.currentOwner(ctx, sequenceID)
+	if err != nil {
+		return outAnnotations, err
+	}
+
+	if currentOwnerID == userID {
+		outAnnotations.Update(&v2.GrantAlreadyExists{})
+		return outAnnotations, nil
+	}
+
+	rateLimitData, err := b.client.UpdateSequenceOwner(ctx, sequenceID, userID)
+	if err != nil {
+		if rateLimitData != nil {
+			outAnnotations.WithRateLimiting(rateLimitData)
+		}
+		return outAnnotations, err
+	}
+
+	return outAnnotations, nil
+}
+
+// Revoke can't take the ownership away, since every sequence has an owner: it has to be granted to another user.
+// The revoke succeeds when the user no longer owns the sequence.
+func (b *sequenceBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	ctx = client.ContextWithProvisioning(ctx)
+
+	if grant.Entitlement.Id != sequenceOwnerEntitlementID(grant.Entitlement.Resource) {
+		return annotations.Annotations{}, status.Errorf(codes.Unimplemented, "outreach: only the ownership of a sequence can be revoked, got %s", grant.Entitlement.Id)
+	}
+
+	sequenceID := grant.Entitlement.Resource.Id.Resource
+	userID, err := strconv.Atoi(grant.Principal.Id.Resource)
+	if err != nil {
+		return annotations.Annotations{}, err
+	}
+
+	currentOwnerID, outAnnotations, err := b.currentOwner(ctx, sequenceID)
+	if err != nil {
+		return outAnnotations, err
+	}
+
+	if currentOwnerID != userID {
+		outAnnotations.Update(&v2.GrantAlreadyRevoked{})
+		return outAnnotations, nil
+	}
+
+	return outAnnotations, status.Errorf(
+		codes.FailedPrecondition,
+		"outreach: the sequence {%s} must have an owner, grant its ownership to another user instead",
+		sequenceID,
+	)
+}
+
+// currentOwner returns the ID of the user owning the sequence, or 0 when it has none.
+func (b *sequenceBuilder) currentOwner(ctx context.Context, sequenceID string) (int, annotations.Annotations, error) {
+	outAnnotations := annotations.Annotations{}
+
+	sequence, rateLimitData, err := b.client.GetSequenceByID(ctx, sequenceID)
+	if err != nil {
+		if rateLimitData != nil {
+			outAnnotations.WithRateLimiting(rateLimitData)
+		}
+		return 0, outAnnotations, err
+	}
+
+	return sequence.OwnerID(), outAnnotations, nil
+}
+
+func (b *sequenceBuilder) setRelationships(sequenceID string, relationships *client.SequenceRelationships) {
+	b.relationshipsMu.Lock()
+	defer b.relationshipsMu.Unlock()
+
+	b.relationships[sequenceID] = relationships
+}
+
+func (b *sequenceBuilder) getRelationships(sequenceID string) (*client.SequenceRelationships, bool) {
+	b.relationshipsMu.RLock()
+	defer b.relationshipsMu.RUnlock()
+
+	relationships, ok := b.relationships[sequenceID]
+	return relationships, ok
+}
+
+func sequenceOwnerEntitlementID(resource *v2.Resource) string {
+	return entitlement.NewEntitlementID(resource, sequenceOwnerEntitlementName)
+}
+
+// parseIntoSequenceResource names the sequence after its name. Like mailboxes, sequences have no trait to hold a
+// profile, so the share type is given in the description.
+func parseIntoSequenceResource(sequence client.Sequence) (*v2.Resource, error) {
+	description := "share type: " + sequence.Attributes.ShareType
+	if !sequence.Attributes.Enabled {
+		description += ", disabled"
+	}
+
+	ret, err := rs.NewResource(
+		sequence.Attributes.Name,
+		sequenceResourceType,
+		sequence.Id,
+		rs.WithDescription(description),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func newSequenceBuilder(c *client.OutreachClient) *sequenceBuilder {
+	return &sequenceBuilder{
+		client:        c,
+		relationships: make(map[string]*client.SequenceRelationships),
+	}
+}
@@ -0,0 +1,307 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	"github.com/conductorone/baton-outreach/pkg/outreachtest"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"google.golang.org/grpc/codes"
+)
+
+func TestSequenceBuilderList(t *testing.T) {
+	ctx := context.Background()
+	_, c := newTestServer(t, client.WithPageSize(2))
+	builder := newSequenceBuilder(c)
+
+	descriptions := make(map[string]string)
+	var token string
+	for {
+		sequences, nextToken, _, err := builder.List(ctx, nil, &pagination.Token{Token: token})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, sequence := range sequences {
+			descriptions[sequence.DisplayName] = sequence.Description
+		}
+
+		if nextToken == "" {
+			break
+		}
+		token = nextToken
+	}
+
+	want := map[string]string{
+		"Enterprise outbound": "share type: shared",
+		"Follow-up":           "share type: private, disabled",
+		"Onboarding":          "share type: read_only",
+	}
+	if len(descriptions) != len(want) {
+		t.Fatalf("expected the sequences %v, got %v", want, descriptions)
+	}
+	for name, wantDescription := range want {
+		if descriptions[name] != wantDescription {
+			t.Errorf("expected the sequence %s to be described as %q, got %q", name, wantDescription, descriptions[name])
+		}
+	}
+}
+
+func TestSequenceBuilderEntitlements(t *testing.T) {
+	ctx := context.Background()
+	_, c := newTestServer(t)
+	builder := newSequenceBuilder(c)
+
+	entitlements, _, _, err := builder.Entitlements(ctx, resourceOf(sequenceResourceType, "1"), &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grantableTo := make(map[string][]string)
+	for _, e := range entitlements {
+		grantableTo[e.Slug] = []string{}
+		for _, resourceType := range e.GrantableTo {
+			grantableTo[e.Slug] = append(grantableTo[e.Slug], resourceType.Id)
+		}
+	}
+
+	want := map[string][]string{
+		sequenceOwnerEntitlementName:  {userResourceType.Id},
+		sequenceSharedEntitlementName: {teamResourceType.Id},
+	}
+	if len(grantableTo) != len(want) {
+		t.Fatalf("expected the entitlements %v, got %v", want, grantableTo)
+	}
+	for slug, wantGrantableTo := range want {
+		if !slices.Equal(grantableTo[slug], wantGrantableTo) {
+			t.Errorf("expected the %s entitlement to be grantable to %v, got %v", slug, wantGrantableTo, grantableTo[slug])
+		}
+	}
+}
+
+func TestSequenceBuilderGrants(t *testing.T) {
+	tests := []struct {
+		name       string
+		sequenceID string
+		listFirst  bool
+		wantGrants []string
+		wantCode   codes.Code
+		wantFetch  bool
+	}{
+		{
+			name:       "from the listed sequences",
+			sequenceID: "3",
+			listFirst:  true,
+			wantGrants: []string{"owner:user:4", "shared:team:1", "shared:team:2"},
+		},
+		{
+			name:       "sequence missing from the listed sequences",
+			sequenceID: "1",
+			wantGrants: []string{"owner:user:2", "shared:team:2"},
+			wantFetch:  true,
+		},
+		{
+			name:       "private sequence",
+			sequenceID: "2",
+			listFirst:  true,
+			wantGrants: []string{"owner:user:1"},
+		},
+		{
+			name:       "unknown sequence",
+			sequenceID: "42",
+			wantCode:   codes.NotFound,
+			wantFetch:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t)
+			builder := newSequenceBuilder(c)
+
+			if tt.listFirst {
+				_, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			grants, _, _, err := builder.Grants(ctx, resourceOf(sequenceResourceType, tt.sequenceID), &pagination.Token{})
+			checkCode(t, err, tt.wantCode)
+
+			fetched := slices.ContainsFunc(server.Requests(), func(r outreachtest.Request) bool {
+				return r.Method == http.MethodGet && r.Path == "/sequences/"+tt.sequenceID
+			})
+			if fetched != tt.wantFetch {
+				t.Errorf("expected the sequence to be requested: %t, got %t", tt.wantFetch, fetched)
+			}
+
+			if err != nil {
+				return
+			}
+
+			var got []string
+			for _, g := range grants {
+				permission := strings.TrimPrefix(g.Entitlement.Id, sequenceResourceType.Id+":"+tt.sequenceID+":")
+				got = append(got, permission+":"+g.Principal.Id.ResourceType+":"+g.Principal.Id.Resource)
+
+				// The teams the sequence is shared with are expanded to their members.
+				expandable := &v2.GrantExpandable{}
+				grantAnnotations := annotations.Annotations(g.Annotations)
+				ok, err := grantAnnotations.Pick(expandable)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if isTeam := g.Principal.Id.ResourceType == teamResourceType.Id; ok != isTeam {
+					t.Errorf("expected the grant to %s to be expandable: %t, got %t", g.Principal.Id.Resource, isTeam, ok)
+				}
+			}
+
+			if !slices.Equal(got, tt.wantGrants) {
+				t.Errorf("expected the grants %v, got %v", tt.wantGrants, got)
+			}
+		})
+	}
+}
+
+func TestSequenceBuilderGrantsGrantableTo(t *testing.T) {
+	ctx := context.Background()
+	_, c := newTestServer(t)
+	builder := newSequenceBuilder(c)
+
+	for _, sequence := range listAll(ctx, t, builder) {
+		entitlements, _, _, err := builder.Entitlements(ctx, sequence, &pagination.Token{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		grantableTo := make(map[string][]string)
+		for _, e := range entitlements {
+			for _, resourceType := range e.GrantableTo {
+				grantableTo[e.Id] = append(grantableTo[e.Id], resourceType.Id)
+			}
+		}
+
+		for _, g := range grantsOf(ctx, t, builder, sequence) {
+			if !slices.Contains(grantableTo[g.Entitlement.Id], g.Principal.Id.ResourceType) {
+				t.Errorf(
+					"expected the %s entitlement to be grantable to the %s principal of its grant, grantable to %v",
+					g.Entitlement.Id,
+					g.Principal.Id.ResourceType,
+					grantableTo[g.Entitlement.Id],
+				)
+			}
+		}
+	}
+}
+
+func TestSequenceBuilderGrant(t *testing.T) {
+	tests := []struct {
+		name          string
+		sequenceID    string
+		permission    string
+		userID        string
+		wantOwner     int
+		wantCode      codes.Code
+		alreadyExists bool
+	}{
+		{
+			name:       "ownership of a departed user",
+			sequenceID: "3",
+			permission: sequenceOwnerEntitlementName,
+			userID:     "2",
+			wantOwner:  2,
+		},
+		{
+			name:          "current owner",
+			sequenceID:    "1",
+			permission:    sequenceOwnerEntitlementName,
+			userID:        "2",
+			wantOwner:     2,
+			alreadyExists: true,
+		},
+		{
+			name:       "unknown user",
+			sequenceID: "1",
+			permission: sequenceOwnerEntitlementName,
+			userID:     "42",
+			wantOwner:  2,
+			wantCode:   codes.InvalidArgument,
+		},
+		{
+			name:       "shared with",
+			sequenceID: "2",
+			permission: sequenceSharedEntitlementName,
+			userID:     "2",
+			wantOwner:  1,
+			wantCode:   codes.Unimplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t)
+			sequence := resourceOf(sequenceResourceType, tt.sequenceID)
+
+			annos, err := newSequenceBuilder(c).Grant(ctx, resourceOf(userResourceType, tt.userID), entitlementOf(sequence, tt.permission))
+			checkCode(t, err, tt.wantCode)
+
+			if got := annos.Contains(&v2.GrantAlreadyExists{}); got != tt.alreadyExists {
+				t.Errorf("expected GrantAlreadyExists to be %t, got %t", tt.alreadyExists, got)
+			}
+
+			checkRelationship(t, server, "sequence", tt.sequenceID, (*client.Sequence).OwnerID, tt.wantOwner)
+		})
+	}
+}
+
+func TestSequenceBuilderRevoke(t *testing.T) {
+	tests := []struct {
+		name           string
+		sequenceID     string
+		userID         string
+		wantCode       codes.Code
+		alreadyRevoked bool
+	}{
+		{
+			name:       "current owner",
+			sequenceID: "1",
+			userID:     "2",
+			wantCode:   codes.FailedPrecondition,
+		},
+		{
+			name:           "ownership already transferred",
+			sequenceID:     "1",
+			userID:         "1",
+			alreadyRevoked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t)
+			sequence := resourceOf(sequenceResourceType, tt.sequenceID)
+
+			annos, err := newSequenceBuilder(c).Revoke(ctx, &v2.Grant{
+				Entitlement: entitlementOf(sequence, sequenceOwnerEntitlementName),
+				Principal:   resourceOf(userResourceType, tt.userID),
+			})
+			checkCode(t, err, tt.wantCode)
+
+			if got := annos.Contains(&v2.GrantAlreadyRevoked{}); got != tt.alreadyRevoked {
+				t.Errorf("expected GrantAlreadyRevoked to be %t, got %t", tt.alreadyRevoked, got)
+			}
+
+			checkRelationship(t, server, "sequence", tt.sequenceID, (*client.Sequence).OwnerID, 2)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package connector
+
+import (
+	"context"
+	"sync"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultTeamFetchWorkers is the number of teams fetched concurrently by default.
+	DefaultTeamFetchWorkers = 4
+	// maxPrefetchedTeams bounds the prefetched pages of members kept in memory, which hold up to a page of users each.
+	maxPrefetchedTeams = 32
+)
+
+// teamMembersPage is a page of the members of a team, with the cursor of the next one.
+type teamMembersPage struct {
+	members    []*client.User
+	nextCursor string
+}
+
+// teamMembers prefetches the first page of members of the listed teams, so most of their grants are served from memory
+// instead of requesting the teams one at a time. When Grants asks for a team that wasn't prefetched, the members of
+// the teams listed after it are requested along with it, up to maxPrefetchedTeams pages held at once. Every request
+// still goes through the rate limiter of the client, and the prefetch stops as soon as the limiter starts spreading
+// the requests, leaving the remaining teams to be requested on their own.
+type teamMembers struct {
+	client  *client.OutreachClient
+	workers int
+
+	mu sync.Mutex
+	// teamIDs are the listed teams in order, and pending the position of the ones not prefetched yet.
+	teamIDs []string
+	pending map[string]int
+	pages   map[string]*teamMembersPage
+}
+
+func newTeamMembers(c *client.OutreachClient, workers int) *teamMembers {
+	return &teamMembers{
+		client:  c,
+		workers: workers,
+		pending: make(map[string]int),
+		pages:   make(map[string]*teamMembersPage),
+	}
+}
+
+// reset forgets the listed teams and drops the prefetched pages, when a new sync starts listing the teams.
+func (m *teamMembers) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.teamIDs = nil
+	m.pending = make(map[string]int)
+	m.pages = make(map[string]*teamMembersPage)
+}
+
+// listed records the listed teams, in order, so their members can be prefetched ahead of Grants.
+func (m *teamMembers) listed(teamIDs ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, teamID := range teamIDs {
+		if _, ok := m.pending[teamID]; ok {
+			continue
+		}
+
+		m.pending[teamID] = len(m.teamIDs)
+		m.teamIDs = append(m.teamIDs, teamID)
+	}
+}
+
+// page returns a page of members of the team. The first page is taken from the prefetched ones, prefetching the team
+// and the ones listed after it when it is missing. Prefetched pages are dropped once served, so a later request of the
+// same team gets fresh members.
+func (m *teamMembers) page(ctx context.Context, teamID, cursor string) (*teamMembersPage, *v2.RateLimitDescription, error) {
+	if cursor == "" {
+		if page, ok := m.take(teamID); ok {
+			return page, nil, nil
+		}
+
+		m.prefetch(ctx, teamID)
+		if page, ok := m.take(teamID); ok {
+			return page, nil, nil
+		}
+	}
+
+	members, nextCursor, rateLimitData, err := m.client.ListAllTeamMembers(ctx, teamID, cursor)
+	if err != nil {
+		return nil, rateLimitData, err
+	}
+
+	return &teamMembersPage{members: members, nextCursor: nextCursor}, rateLimitData, nil
+}
+
+// prefetch requests the first page of members of the team and of the pending teams listed after it, with up to the
+// configured number of concurrent requests. Failed requests are only logged, since the page is requested again and
+// the error reported then.
+func (m *teamMembers) prefetch(ctx context.Context, teamID string) {
+	if m.workers <= 0 {
+		return
+	}
+
+	teamIDs := m.window(teamID)
+	if len(teamIDs) == 0 {
+		return
+	}
+
+	logger := ctxzap.Extract(ctx)
+	pending := make(chan string)
+
+	var wg sync.WaitGroup
+	for range min(m.workers, len(teamIDs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for teamID := range pending {
+				members, nextCursor, _, err := m.client.ListAllTeamMembers(ctx, teamID, "")
+				if err != nil {
+					logger.Debug("unable to prefetch the team members", zap.String("team_id", teamID), zap.Error(err))
+					continue
+				}
+
+				m.set(teamID, &teamMembersPage{members: members, nextCursor: nextCursor})
+			}
+		}()
+	}
+
+	for i, teamID := range teamIDs {
+		if ctx.Err() != nil || m.client.Throttled() {
+			logger.Debug("stopping the prefetch of the team members", zap.Int("remaining_teams", len(teamIDs)-i))
+			break
+		}
+
+		m.dispatched(teamID)
+		pending <- teamID
+	}
+	close(pending)
+
+	wg.Wait()
+}
+
+// window returns the pending teams to prefetch along with the team: the team itself, when it was listed, followed by
+// the ones listed after it, as long as the prefetched pages stay within maxPrefetchedTeams.
+func (m *teamMembers) window(teamID string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	position, ok := m.pending[teamID]
+	if !ok {
+		return nil
+	}
+
+	teamIDs := []string{teamID}
+	for _, nextTeamID := range m.teamIDs[position+1:] {
+		if len(m.pages)+len(teamIDs) >= maxPrefetchedTeams {
+			break
+		}
+
+		if _, ok := m.pending[nextTeamID]; ok {
+			teamIDs = append(teamIDs, nextTeamID)
+		}
+	}
+
+	return teamIDs
+}
+
+// dispatched marks the team as no longer pending, once its members are requested.
+func (m *teamMembers) dispatched(teamID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.pending, teamID)
+}
+
+// take removes the prefetched page of the team, reporting whether there was one.
+func (m *teamMembers) take(teamID string) (*teamMembersPage, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	page, ok := m.pages[teamID]
+	delete(m.pages, teamID)
+
+	return page, ok
+}
+
+func (m *teamMembers) set(teamID string, page *teamMembersPage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pages[teamID] = page
+}
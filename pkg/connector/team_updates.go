@@ -0,0 +1,105 @@
+package connector
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// teamUpdateAttempts is how many times the members of a team are updated before giving up on a change
+// that keeps being overwritten.
+const teamUpdateAttempts = 3
+
+// changeMembership makes the user a member of the team or removes it from the team, and reports whether the members
+// had to be changed. With batching enabled, the change is submitted with the others of the same window, and it is only
+// made with its own updates when the batched one was overwritten. The listed teams of the user are forgotten afterwards.
+func (b *teamBuilder) changeMembership(ctx context.Context, teamID string, userID int, member bool) (bool, annotations.Annotations, error) {
+	defer b.users.forget(strconv.Itoa(userID))
+
+	if b.client.Batching() {
+		changed, err := b.client.BatchUpdateTeamMembership(ctx, teamID, userID, member)
+		if status.Code(err) != codes.Aborted {
+			return changed, annotations.Annotations{}, err
+		}
+
+		ctxzap.Extract(ctx).Warn(
+			"the batched update of the team members was overwritten, updating the team on its own",
+			zap.String("team_id", teamID),
+			zap.Int("user_id", userID),
+		)
+	}
+
+	return b.updateMembership(ctx, teamID, userID, member)
+}
+
+// updateMembership makes the user a member of the team or removes it from the team, and reports whether the members
+// had to be changed. The members are read again after each update, and the update is retried with them while the
+// change is missing, since an update made at the same time by another process can overwrite it.
+func (b *teamBuilder) updateMembership(ctx context.Context, teamID string, userID int, member bool) (bool, annotations.Annotations, error) {
+	outAnnotations := annotations.Annotations{}
+	logger := ctxzap.Extract(ctx)
+
+	unlock := b.client.LockTeams(teamID)
+	defer unlock()
+
+	teamMembers, rateLimitData, err := b.client.GetTeamMembers(ctx, teamID)
+	if err != nil {
+		if rateLimitData != nil {
+			outAnnotations.WithRateLimiting(rateLimitData)
+		}
+		return false, outAnnotations, err
+	}
+
+	if client.IsTeamMember(teamMembers, userID) == member {
+		return false, outAnnotations, nil
+	}
+
+	for attempt := 1; attempt <= teamUpdateAttempts; attempt++ {
+		rateLimitData, err = b.client.UpdateTeamMembers(ctx, teamID, client.WithTeamMember(teamMembers, userID, member))
+		if err != nil {
+			if rateLimitData != nil {
+				outAnnotations.WithRateLimiting(rateLimitData)
+			}
+			return false, outAnnotations, err
+		}
+
+		teamMembers, rateLimitData, err = b.client.GetTeamMembers(ctx, teamID)
+		if err != nil {
+			if rateLimitData != nil {
+				outAnnotations.WithRateLimiting(rateLimitData)
+			}
+			return false, outAnnotations, err
+		}
+
+		if client.IsTeamMember(teamMembers, userID) == member {
+			return true, outAnnotations, nil
+		}
+
+		logger.Warn(
+			"the update of the team members was overwritten, retrying",
+			zap.String("team_id", teamID),
+			zap.Int("user_id", userID),
+			zap.Int("attempt", attempt),
+		)
+	}
+
+	change := "added to"
+	if !member {
+		change = "removed from"
+	}
+
+	return false, outAnnotations, status.Errorf(
+		codes.Aborted,
+		"outreach: the user {%d} could not be %s the team {%s}, the members were still overwritten after %d updates",
+		userID,
+		change,
+		teamID,
+		teamUpdateAttempts,
+	)
+}
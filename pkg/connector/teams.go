@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/conductorone/baton-outreach/pkg/connector/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
@@ -13,12 +14,19 @@ import (
 	"github.com/conductorone/baton-sdk/pkg/types/grant"
 	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const teamPermissionName = "member"
 
 type teamBuilder struct {
 	client *client.OutreachClient
+	// users has the teams of the listed users, which the memberships are taken from when all of them are known.
+	users   *listedUsers
+	teams   *deltaList[client.Team]
+	members *teamMembers
 }
 
 func (b *teamBuilder) ResourceType(_ context.Context) *v2.ResourceType {
@@ -26,18 +34,14 @@ func (b *teamBuilder) ResourceType(_ context.Context) *v2.ResourceType {
 }
 
 func (b *teamBuilder) List(ctx context.Context, _ *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
-	var (
-		teamResources []*v2.Resource
-		nextPageToken string
-	)
+	var teamResources []*v2.Resource
 	outAnnotations := annotations.Annotations{}
 
-	bag, nextPage, err := client.GetToken(pToken.Token, &v2.ResourceId{ResourceType: teamResourceType.Id})
-	if err != nil {
-		return nil, "", nil, err
+	if pToken.Token == "" {
+		b.members.reset()
 	}
 
-	teams, nextPageLink, rateLimitData, err := b.client.ListAllTeams(ctx, nextPage)
+	teams, nextPageToken, rateLimitData, err := b.teams.page(ctx, pToken.Token)
 	if err != nil {
 		if rateLimitData != nil {
 			outAnnotations.WithRateLimiting(rateLimitData)
@@ -52,13 +56,7 @@ func (b *teamBuilder) List(ctx context.Context, _ *v2.ResourceId, pToken *pagina
 		}
 
 		teamResources = append(teamResources, teamResource)
-	}
-
-	if nextPageLink != "" {
-		nextPageToken, err = bag.NextToken(nextPageLink)
-		if err != nil {
-			return nil, "", outAnnotations, err
-		}
+		b.members.listed(teamResource.Id.Resource)
 	}
 
 	return teamResources, nextPageToken, outAnnotations, nil
@@ -79,14 +77,36 @@ func (b *teamBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *
 	return []*v2.Entitlement{entitlement.NewAssignmentEntitlement(resource, teamPermissionName, assigmentOptions...)}, "", outAnnotations, nil
 }
 
-func (b *teamBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
-	var grantResources []*v2.Grant
+// Grants returns the members of the team from the teams of the listed users. When some of them are unknown, or the team
+// was updated since the users it was taken from, the members are requested a page at a time instead, and the first page
+// is usually prefetched along with the teams listed before it.
+func (b *teamBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	var (
+		grantResources []*v2.Grant
+		nextPageToken  string
+	)
 	outAnnotations := annotations.Annotations{}
 	logger := ctxzap.Extract(ctx)
 
 	teamID := resource.Id.Resource
 
-	teamDetails, rateLimitData, err := b.client.GetTeamByID(ctx, teamID)
+	bag, cursor, err := client.GetToken(pToken.Token, resource.Id)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if cursor == "" {
+		updatedAt, ok := teamUpdatedAt(resource)
+		if memberIDs, known := b.users.members(teamID, updatedAt); ok && known {
+			for _, memberID := range memberIDs {
+				grantResources = append(grantResources, newMemberGrant(resource, memberID))
+			}
+
+			return grantResources, "", outAnnotations, nil
+		}
+	}
+
+	page, rateLimitData, err := b.members.page(ctx, teamID, cursor)
 	if err != nil {
 		if rateLimitData != nil {
 			outAnnotations.WithRateLimiting(rateLimitData)
@@ -94,74 +114,62 @@ func (b *teamBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagi
 		return nil, "", outAnnotations, err
 	}
 
-	if teamDetails.Relationships == nil || teamDetails.Relationships.Users == nil || teamDetails.Relationships.Users.Data == nil {
-		logger.Warn(fmt.Sprintf("the team {%s} does not have any members", teamID))
-		return nil, "", outAnnotations, nil
+	// The team can be deleted between List and Grants, which shouldn't abort the whole sync. Outreach lists no members
+	// for a deleted team instead of failing, so the team is requested to tell it apart from a team without members.
+	if cursor == "" && len(page.members) == 0 {
+		_, rateLimitData, err = b.client.GetTeamByID(client.ContextWithFreshRead(ctx), teamID)
+		if err != nil {
+			if rateLimitData != nil {
+				outAnnotations.WithRateLimiting(rateLimitData)
+			}
+
+			if status.Code(err) == codes.NotFound {
+				logger.Warn(fmt.Sprintf("the team {%s} was not found, skipping its grants", teamID), zap.Error(err))
+				return nil, "", outAnnotations, nil
+			}
+			return nil, "", outAnnotations, err
+		}
 	}
 
-	teamMembers := *teamDetails.Relationships.Users.Data
-	for _, member := range teamMembers {
-		userResource := &v2.Resource{
-			Id: &v2.ResourceId{
-				ResourceType: userResourceType.Id,
-				Resource:     strconv.Itoa(member.Id),
-			},
-		}
+	for _, member := range page.members {
+		grantResources = append(grantResources, newMemberGrant(resource, strconv.Itoa(member.Id)))
+	}
 
-		grantResources = append(grantResources, grant.NewGrant(resource, teamPermissionName, userResource))
+	if page.nextCursor != "" {
+		nextPageToken, err = bag.NextToken(page.nextCursor)
+		if err != nil {
+			return nil, "", outAnnotations, err
+		}
 	}
 
-	return grantResources, "", outAnnotations, nil
+	return grantResources, nextPageToken, outAnnotations, nil
 }
 
+// Grant adds the user to the complete list of members of the team, since updating the members replaces all of them.
 func (b *teamBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
-	var teamMembers []client.DataDetailPair
-	outAnnotations := annotations.Annotations{}
+	ctx = client.ContextWithProvisioning(ctx)
 
 	teamID := entitlement.Resource.Id.Resource
 	userID, err := strconv.Atoi(principal.Id.Resource)
 	if err != nil {
-		return outAnnotations, err
+		return annotations.Annotations{}, err
 	}
 
-	teamDetails, rateLimitData, err := b.client.GetTeamByID(ctx, teamID)
+	changed, outAnnotations, err := b.changeMembership(ctx, teamID, userID, true)
 	if err != nil {
-		if rateLimitData != nil {
-			outAnnotations.WithRateLimiting(rateLimitData)
-		}
 		return outAnnotations, err
 	}
 
-	if teamDetails.Relationships != nil && teamDetails.Relationships.Users != nil && teamDetails.Relationships.Users.Data != nil {
-		teamMembers = *teamDetails.Relationships.Users.Data
-	}
-
-	for _, member := range teamMembers {
-		if member.Id == userID {
-			// It doesn't fail when "re-adding" an existing user to a Team, but to avoid the unnecessary request, I added this validation and the annotation.
-			outAnnotations.Update(&v2.GrantAlreadyExists{})
-			return outAnnotations, nil
-		}
-	}
-	teamMembers = append(teamMembers, client.DataDetailPair{
-		Id:   userID,
-		Type: "user",
-	})
-
-	rateLimitData, err = b.client.UpdateTeamMembers(ctx, teamID, teamMembers)
-	if err != nil {
-		if rateLimitData != nil {
-			outAnnotations.WithRateLimiting(rateLimitData)
-		}
-		return outAnnotations, err
+	if !changed {
+		outAnnotations.Update(&v2.GrantAlreadyExists{})
 	}
 
 	return outAnnotations, nil
 }
 
+// Revoke removes the user from the complete list of members of the team.
 func (b *teamBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
-	outAnnotations := annotations.Annotations{}
-	updatedTeamMembers := make([]client.DataDetailPair, 0)
+	ctx = client.ContextWithProvisioning(ctx)
 
 	teamID := grant.Entitlement.Resource.Id.Resource
 	userID, err := strconv.Atoi(grant.Principal.Id.Resource)
@@ -169,36 +177,47 @@ func (b *teamBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.
 		return nil, err
 	}
 
-	teamDetails, rateLimitData, err := b.client.GetTeamByID(ctx, teamID)
+	changed, outAnnotations, err := b.changeMembership(ctx, teamID, userID, false)
 	if err != nil {
-		if rateLimitData != nil {
-			outAnnotations.WithRateLimiting(rateLimitData)
-		}
 		return outAnnotations, err
 	}
 
-	if teamDetails.Relationships == nil || teamDetails.Relationships.Users == nil || teamDetails.Relationships.Users.Data == nil {
-		return nil, fmt.Errorf("revoke tried on the team {%s} but the members list was not accessible", teamID)
+	if !changed {
+		outAnnotations.Update(&v2.GrantAlreadyRevoked{})
 	}
 
-	teamMembers := *teamDetails.Relationships.Users.Data
-	for _, member := range teamMembers {
-		if member.Id == userID {
-			continue
-		}
+	return outAnnotations, nil
+}
+
+// teamUpdatedAt returns when the team was last updated, from its profile.
+func teamUpdatedAt(resource *v2.Resource) (time.Time, bool) {
+	groupTrait, err := rs.GetGroupTrait(resource)
+	if err != nil {
+		return time.Time{}, false
+	}
 
-		updatedTeamMembers = append(updatedTeamMembers, member)
+	value, ok := rs.GetProfileStringValue(groupTrait.Profile, "updated_at")
+	if !ok {
+		return time.Time{}, false
 	}
 
-	rateLimitData, err = b.client.UpdateTeamMembers(ctx, teamID, updatedTeamMembers)
+	updatedAt, err := time.Parse(time.RFC3339, value)
 	if err != nil {
-		if rateLimitData != nil {
-			outAnnotations.WithRateLimiting(rateLimitData)
-		}
-		return outAnnotations, err
+		return time.Time{}, false
 	}
 
-	return outAnnotations, nil
+	return updatedAt, true
+}
+
+func newMemberGrant(team *v2.Resource, userID string) *v2.Grant {
+	userResource := &v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: userResourceType.Id,
+			Resource:     userID,
+		},
+	}
+
+	return grant.NewGrant(team, teamPermissionName, userResource)
 }
 
 func parseIntoTeamResource(team client.Team) (*v2.Resource, error) {
@@ -225,8 +244,13 @@ func parseIntoTeamResource(team client.Team) (*v2.Resource, error) {
 	return ret, nil
 }
 
-func newTeamBuilder(c *client.OutreachClient) *teamBuilder {
+func newTeamBuilder(c *client.OutreachClient, users *listedUsers, fullSyncInterval time.Duration, fetchWorkers int) *teamBuilder {
 	return &teamBuilder{
 		client: c,
+		users:  users,
+		teams: newDeltaList(teamResourceType.Id, fullSyncInterval, c.ListAllTeams, func(team *client.Team) int {
+			return team.Id
+		}),
+		members: newTeamMembers(c, fetchWorkers),
 	}
 }
@@ -0,0 +1,772 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	"github.com/conductorone/baton-outreach/pkg/outreachtest"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"google.golang.org/grpc/codes"
+)
+
+func TestTeamBuilderList(t *testing.T) {
+	ctx := context.Background()
+	_, c := newTestServer(t, client.WithPageSize(2))
+	builder := newTeamBuilder(c, newListedUsers(), 0, 0)
+
+	var (
+		teams []*v2.Resource
+		token string
+	)
+	for {
+		page, nextToken, _, err := builder.List(ctx, nil, &pagination.Token{Token: token})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		teams = append(teams, page...)
+		if nextToken == "" {
+			break
+		}
+		token = nextToken
+	}
+
+	if got := resourceIDs(teams); !slices.Equal(got, []string{"1", "2", "3"}) {
+		t.Fatalf("unexpected teams %v", got)
+	}
+
+	entitlements, _, _, err := builder.Entitlements(ctx, teams[0], &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entitlements) != 1 || entitlements[0].Slug != teamPermissionName || entitlements[0].DisplayName != "Member of Engineering" {
+		t.Errorf("unexpected entitlements %v", entitlements)
+	}
+}
+
+func TestTeamBuilderGrants(t *testing.T) {
+	tests := []struct {
+		name        string
+		teamID      string
+		wantMembers []string
+		wantCode    codes.Code
+		// wantTeamRead tells whether the team is requested to find out if it was deleted, when it has no members.
+		wantTeamRead bool
+	}{
+		{
+			name:        "team with members",
+			teamID:      "2",
+			wantMembers: []string{"2", "4"},
+		},
+		{
+			name:         "team without members",
+			teamID:       "3",
+			wantTeamRead: true,
+		},
+		{
+			name:         "team deleted since listed",
+			teamID:       "42",
+			wantTeamRead: true,
+		},
+		{
+			name:        "team with more members than embedded in the team",
+			teamID:      "4",
+			wantMembers: []string{"1", "2", "3", "4", "5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t, client.WithPageSize(2))
+			server.SetLinkageLimit(2)
+			seedLargeTeam(t, server)
+
+			builder := newTeamBuilder(c, newListedUsers(), 0, 0)
+			team := resourceOf(teamResourceType, tt.teamID)
+
+			var (
+				grants []*v2.Grant
+				token  string
+			)
+			for {
+				page, nextToken, _, err := builder.Grants(ctx, team, &pagination.Token{Token: token})
+				checkCode(t, err, tt.wantCode)
+				if err != nil {
+					return
+				}
+
+				grants = append(grants, page...)
+				if nextToken == "" {
+					break
+				}
+				token = nextToken
+			}
+
+			if got := principalIDs(grants); !slices.Equal(got, tt.wantMembers) {
+				t.Errorf("expected the members %v, got %v", tt.wantMembers, got)
+			}
+
+			teamRead := slices.ContainsFunc(server.Requests(), func(request outreachtest.Request) bool {
+				return request.Method == http.MethodGet && request.Path == "/teams/"+tt.teamID
+			})
+			if teamRead != tt.wantTeamRead {
+				t.Errorf("expected the team to be requested to be %t, got %t", tt.wantTeamRead, teamRead)
+			}
+		})
+	}
+}
+
+func TestTeamBuilderGrantsFromListedUsers(t *testing.T) {
+	tests := []struct {
+		name string
+		// userPages is the number of pages of users listed before the grants, all of them when zero.
+		userPages    int
+		linkageLimit int
+		// grantUser is made a member of the team after the users are listed, when set.
+		grantUser     string
+		wantMembers   []string
+		wantRequested bool
+	}{
+		{
+			name:        "every user listed",
+			wantMembers: []string{"2", "4"},
+		},
+		{
+			name:          "users partly listed",
+			userPages:     1,
+			wantMembers:   []string{"2", "4"},
+			wantRequested: true,
+		},
+		{
+			name:          "teams of a user cut off",
+			linkageLimit:  1,
+			wantMembers:   []string{"2", "4"},
+			wantRequested: true,
+		},
+		{
+			name:          "member added since listed",
+			grantUser:     "3",
+			wantMembers:   []string{"2", "3", "4"},
+			wantRequested: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t, client.WithPageSize(2))
+			server.SetLinkageLimit(tt.linkageLimit)
+
+			users := newListedUsers()
+			userBuilder := newUserBuilder(c, users, 0, true)
+			var token string
+			for page := 1; ; page++ {
+				_, nextToken, _, err := userBuilder.List(ctx, nil, &pagination.Token{Token: token})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if nextToken == "" || page == tt.userPages {
+					break
+				}
+				token = nextToken
+			}
+
+			builder := newTeamBuilder(c, users, 0, 0)
+			team := listedTeam(ctx, t, builder, "2")
+			if tt.grantUser != "" {
+				if _, err := builder.Grant(ctx, resourceOf(userResourceType, tt.grantUser), entitlementOf(team, teamPermissionName)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			requests := countTeamMembersRequests(server)
+
+			grants := grantsOf(ctx, t, builder, team)
+			if got := principalIDs(grants); !slices.Equal(got, tt.wantMembers) {
+				t.Errorf("expected the members %v, got %v", tt.wantMembers, got)
+			}
+
+			if requested := countTeamMembersRequests(server) > requests; requested != tt.wantRequested {
+				t.Errorf("expected the members to be requested: %t, got %t", tt.wantRequested, requested)
+			}
+		})
+	}
+}
+
+func TestTeamBuilderGrant(t *testing.T) {
+	tests := []struct {
+		name          string
+		teamID        string
+		userID        string
+		wantMembers   []int
+		wantCode      codes.Code
+		alreadyExists bool
+	}{
+		{
+			name:        "new member",
+			teamID:      "1",
+			userID:      "3",
+			wantMembers: []int{1, 2, 3},
+		},
+		{
+			name:        "first member",
+			teamID:      "3",
+			userID:      "1",
+			wantMembers: []int{1},
+		},
+		{
+			name:          "existing member",
+			teamID:        "1",
+			userID:        "2",
+			wantMembers:   []int{1, 2},
+			alreadyExists: true,
+		},
+		{
+			name:          "existing member missing from the members embedded in the team",
+			teamID:        "4",
+			userID:        "5",
+			wantMembers:   []int{1, 2, 3, 4, 5},
+			alreadyExists: true,
+		},
+		{
+			name:     "unknown team",
+			teamID:   "42",
+			userID:   "1",
+			wantCode: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t, client.WithPageSize(2))
+			server.SetLinkageLimit(2)
+			seedLargeTeam(t, server)
+			team := resourceOf(teamResourceType, tt.teamID)
+
+			annos, err := newTeamBuilder(c, newListedUsers(), 0, 0).Grant(ctx, resourceOf(userResourceType, tt.userID), entitlementOf(team, teamPermissionName))
+			checkCode(t, err, tt.wantCode)
+			if err != nil {
+				return
+			}
+
+			if got := annos.Contains(&v2.GrantAlreadyExists{}); got != tt.alreadyExists {
+				t.Errorf("expected the grant to already exist: %t, got %t", tt.alreadyExists, got)
+			}
+
+			checkTeamMembers(t, server, tt.teamID, tt.wantMembers)
+		})
+	}
+}
+
+func TestTeamBuilderRevoke(t *testing.T) {
+	tests := []struct {
+		name           string
+		teamID         string
+		userID         string
+		wantMembers    []int
+		wantCode       codes.Code
+		failStatus     int
+		alreadyRevoked bool
+	}{
+		{
+			name:        "member",
+			teamID:      "2",
+			userID:      "4",
+			wantMembers: []int{2},
+		},
+		{
+			name:           "not a member",
+			teamID:         "2",
+			userID:         "1",
+			wantMembers:    []int{2, 4},
+			alreadyRevoked: true,
+		},
+		{
+			name:        "member of a team with more members than embedded in the team",
+			teamID:      "4",
+			userID:      "1",
+			wantMembers: []int{2, 3, 4, 5},
+		},
+		{
+			name:        "update failing",
+			teamID:      "2",
+			userID:      "4",
+			wantMembers: []int{2, 4},
+			wantCode:    codes.Unavailable,
+			failStatus:  http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t, client.WithPageSize(2))
+			server.SetLinkageLimit(2)
+			seedLargeTeam(t, server)
+			if tt.failStatus != 0 {
+				server.Fail(http.MethodPatch, "/teams/"+tt.teamID, tt.failStatus, 1)
+			}
+
+			team := resourceOf(teamResourceType, tt.teamID)
+			grant := &v2.Grant{
+				Entitlement: entitlementOf(team, teamPermissionName),
+				Principal:   resourceOf(userResourceType, tt.userID),
+			}
+
+			annos, err := newTeamBuilder(c, newListedUsers(), 0, 0).Revoke(ctx, grant)
+			checkCode(t, err, tt.wantCode)
+
+			if got := annos.Contains(&v2.GrantAlreadyRevoked{}); got != tt.alreadyRevoked {
+				t.Errorf("expected the grant to already be revoked: %t, got %t", tt.alreadyRevoked, got)
+			}
+
+			checkTeamMembers(t, server, tt.teamID, tt.wantMembers)
+		})
+	}
+}
+
+func TestTeamBuilderPrefetch(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		// wantPrefetched is the number of teams requested by the grants of the first team.
+		wantPrefetched int
+	}{
+		{
+			name:           "prefetched",
+			workers:        2,
+			wantPrefetched: 3,
+		},
+		{
+			name:           "disabled",
+			wantPrefetched: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t)
+			builder := newTeamBuilder(c, newListedUsers(), 0, tt.workers)
+
+			teams, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := countTeamMembersRequests(server); got != 0 {
+				t.Errorf("expected no team to be requested while listing, got %d", got)
+			}
+
+			for i, team := range teams {
+				_, _, _, err := builder.Grants(ctx, team, &pagination.Token{})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if got := countTeamMembersRequests(server); i == 0 && got != tt.wantPrefetched {
+					t.Errorf("expected %d teams to be prefetched, got %d", tt.wantPrefetched, got)
+				}
+			}
+
+			// The members of every team are requested once, either prefetched or from Grants.
+			if got := countTeamMembersRequests(server); got != len(teams) {
+				t.Errorf("expected %d team requests, got %d", len(teams), got)
+			}
+		})
+	}
+}
+
+func TestTeamMembersPrefetchBound(t *testing.T) {
+	ctx := context.Background()
+	server, c := newTestServer(t)
+	members := newTeamMembers(c, 4)
+
+	teamIDs := make([]string, 0, maxPrefetchedTeams+8)
+	for i := range cap(teamIDs) {
+		teamIDs = append(teamIDs, strconv.Itoa(100+i))
+	}
+	members.listed(teamIDs...)
+
+	if _, _, err := members.page(ctx, teamIDs[0], ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first team is served right away, so the others stay below the bound.
+	if got := countTeamMembersRequests(server); got != maxPrefetchedTeams {
+		t.Errorf("expected %d teams to be prefetched, got %d", maxPrefetchedTeams, got)
+	}
+	if got := len(members.pages); got != maxPrefetchedTeams-1 {
+		t.Errorf("expected %d prefetched pages to be kept, got %d", maxPrefetchedTeams-1, got)
+	}
+
+	for _, teamID := range teamIDs[1:] {
+		if _, _, err := members.page(ctx, teamID, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := countTeamMembersRequests(server); got != len(teamIDs) {
+		t.Errorf("expected %d team requests, got %d", len(teamIDs), got)
+	}
+	if got := len(members.pages); got != 0 {
+		t.Errorf("expected the served pages to be dropped, %d are kept", got)
+	}
+}
+
+func TestTeamMembersPrefetchThrottled(t *testing.T) {
+	ctx := context.Background()
+	server, c := newTestServer(t)
+	server.SetRateLimitRemaining(100)
+	members := newTeamMembers(c, 2)
+
+	// The teams are listed like a sync does, which tells the client the budget running low.
+	builder := newTeamBuilder(c, newListedUsers(), 0, 0)
+	builder.members = members
+	if _, _, _, err := builder.List(ctx, nil, &pagination.Token{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The requests of the sync are held back until the budget resets, so the members are left to Grants.
+	members.prefetch(ctx, "1")
+
+	if got := countTeamMembersRequests(server); got != 0 {
+		t.Errorf("expected no team to be prefetched, got %d", got)
+	}
+}
+
+// seedLargeTeam adds the team 4, with the users 1 to 5 as members.
+func seedLargeTeam(t *testing.T, server *outreachtest.Server) {
+	t.Helper()
+
+	err := server.Seed(
+		client.ResourceObject{
+			Id:         5,
+			Type:       "user",
+			Attributes: []byte(`{"name":"Barbara Liskov","email":"barbara@outreachtest.example"}`),
+		},
+		client.ResourceObject{
+			Id:         4,
+			Type:       "team",
+			Attributes: []byte(`{"name":"Everyone"}`),
+			Relationships: map[string]client.Relationship{
+				"users": {Data: []byte(`[{"id":1,"type":"user"},{"id":2,"type":"user"},{"id":3,"type":"user"},{"id":4,"type":"user"},{"id":5,"type":"user"}]`)},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// countTeamMembersRequests counts the requests listing the members of a team.
+func countTeamMembersRequests(server *outreachtest.Server) int {
+	count := 0
+	for _, request := range server.Requests() {
+		if request.Method == http.MethodGet && request.Path == "/users" && request.Query.Get("filter[teams][id]") != "" {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestTeamBuilderGrantOverwritten(t *testing.T) {
+	tests := []struct {
+		name        string
+		dropped     int
+		wantCode    codes.Code
+		wantUpdates int
+		wantMembers []int
+	}{
+		{
+			name:        "overwritten once",
+			dropped:     1,
+			wantUpdates: 2,
+			wantMembers: []int{1, 2, 3},
+		},
+		{
+			name:        "always overwritten",
+			dropped:     teamUpdateAttempts,
+			wantCode:    codes.Aborted,
+			wantUpdates: teamUpdateAttempts,
+			wantMembers: []int{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t)
+			server.DropUpdates("/teams/1", tt.dropped)
+			team := resourceOf(teamResourceType, "1")
+
+			_, err := newTeamBuilder(c, newListedUsers(), 0, 0).Grant(ctx, resourceOf(userResourceType, "3"), entitlementOf(team, teamPermissionName))
+			checkCode(t, err, tt.wantCode)
+
+			updates := 0
+			for _, request := range server.Requests() {
+				if request.Method == http.MethodPatch && request.Path == "/teams/1" {
+					updates++
+				}
+			}
+			if updates != tt.wantUpdates {
+				t.Errorf("expected %d updates of the team, got %d", tt.wantUpdates, updates)
+			}
+
+			checkTeamMembers(t, server, "1", tt.wantMembers)
+		})
+	}
+}
+
+func TestTeamBuilderConcurrentGrants(t *testing.T) {
+	ctx := context.Background()
+	server, c := newTestServer(t)
+	builder := newTeamBuilder(c, newListedUsers(), 0, 0)
+	team := resourceOf(teamResourceType, "1")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for _, userID := range []string{"3", "4"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := builder.Grant(ctx, resourceOf(userResourceType, userID), entitlementOf(team, teamPermissionName))
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkTeamMembers(t, server, "1", []int{1, 2, 3, 4})
+}
+
+func TestTeamBuilderBatchedGrants(t *testing.T) {
+	type membership struct {
+		teamID string
+		userID string
+	}
+
+	tests := []struct {
+		name        string
+		grants      []membership
+		setup       func(server *outreachtest.Server)
+		wantCode    codes.Code
+		wantBatches int
+		wantUpdates int
+		wantMembers map[string][]int
+	}{
+		{
+			name:        "coalesced",
+			grants:      []membership{{"1", "3"}, {"1", "4"}, {"2", "1"}},
+			wantBatches: 1,
+			wantMembers: map[string][]int{"1": {1, 2, 3, 4}, "2": {1, 2, 4}},
+		},
+		{
+			name:        "already a member",
+			grants:      []membership{{"1", "1"}},
+			wantMembers: map[string][]int{"1": {1, 2}},
+		},
+		{
+			name:   "overwritten",
+			grants: []membership{{"1", "3"}},
+			setup: func(server *outreachtest.Server) {
+				server.DropUpdates("/teams/1", 1)
+			},
+			wantBatches: 1,
+			wantUpdates: 1,
+			wantMembers: map[string][]int{"1": {1, 2, 3}},
+		},
+		{
+			name:   "failed item",
+			grants: []membership{{"1", "3"}},
+			setup: func(server *outreachtest.Server) {
+				server.Fail(http.MethodPatch, "/teams/1", http.StatusUnprocessableEntity, 1)
+			},
+			wantCode:    codes.Unknown,
+			wantBatches: 1,
+			wantMembers: map[string][]int{"1": {1, 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t, client.WithBatching(client.BatchOptions{
+				Window:       50 * time.Millisecond,
+				PollInterval: 10 * time.Millisecond,
+			}))
+			if tt.setup != nil {
+				tt.setup(server)
+			}
+			builder := newTeamBuilder(c, newListedUsers(), 0, 0)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, len(tt.grants))
+			for _, m := range tt.grants {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					team := resourceOf(teamResourceType, m.teamID)
+					_, err := builder.Grant(ctx, resourceOf(userResourceType, m.userID), entitlementOf(team, teamPermissionName))
+					errs <- err
+				}()
+			}
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				checkCode(t, err, tt.wantCode)
+			}
+
+			batches, polls, updates := 0, 0, 0
+			for _, request := range server.Requests() {
+				switch {
+				case request.Method == http.MethodPost && request.Path == "/batches":
+					batches++
+				case request.Method == http.MethodGet && strings.HasPrefix(request.Path, "/batches/"):
+					polls++
+				case request.Method == http.MethodPatch && strings.HasPrefix(request.Path, "/teams/"):
+					updates++
+				}
+			}
+			if batches != tt.wantBatches || updates != tt.wantUpdates {
+				t.Errorf("expected %d batches and %d updates of teams, got %d and %d", tt.wantBatches, tt.wantUpdates, batches, updates)
+			}
+			if batches > 0 && polls == 0 {
+				t.Error("expected the batch to be polled until it was complete")
+			}
+
+			for teamID, members := range tt.wantMembers {
+				checkTeamMembers(t, server, teamID, members)
+			}
+		})
+	}
+}
+
+func TestTeamBuilderDeltaGrants(t *testing.T) {
+	tests := []struct {
+		name string
+		// change is applied to the server between the two syncs.
+		change        func(t *testing.T, server *outreachtest.Server)
+		wantMembers   []string
+		wantRequested bool
+	}{
+		{
+			name:        "unchanged team",
+			wantMembers: []string{"1", "2"},
+		},
+		{
+			name: "member added by updating the user",
+			change: func(t *testing.T, server *outreachtest.Server) {
+				seedUser(t, server, 3, time.Now().UTC().Format(time.RFC3339), 1)
+			},
+			wantMembers: []string{"1", "2", "3"},
+		},
+		{
+			name: "member removed by updating the team",
+			change: func(t *testing.T, server *outreachtest.Server) {
+				c, err := server.NewClient(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				_, err = c.UpdateTeamMembers(context.Background(), "1", []client.DataDetailPair{{Id: 1, Type: "user"}})
+				if err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantMembers:   []string{"1"},
+			wantRequested: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t)
+
+			users := newListedUsers()
+			userBuilder := newUserBuilder(c, users, 24*time.Hour, true)
+			builder := newTeamBuilder(c, users, 24*time.Hour, 0)
+			listAll(ctx, t, userBuilder)
+			listAll(ctx, t, builder)
+
+			if tt.change != nil {
+				tt.change(t, server)
+			}
+
+			// The users and the teams updated since the first sync are requested by the second one, the others are reused.
+			listAll(ctx, t, userBuilder)
+			team := listedTeam(ctx, t, builder, "1")
+			requests := countTeamMembersRequests(server)
+
+			if got := principalIDs(grantsOf(ctx, t, builder, team)); !slices.Equal(got, tt.wantMembers) {
+				t.Errorf("expected the members %v, got %v", tt.wantMembers, got)
+			}
+
+			if requested := countTeamMembersRequests(server) > requests; requested != tt.wantRequested {
+				t.Errorf("expected the members to be requested: %t, got %t", tt.wantRequested, requested)
+			}
+		})
+	}
+}
+
+// listedTeam lists the teams like a sync does, and returns the listed team.
+func listedTeam(ctx context.Context, t *testing.T, builder *teamBuilder, teamID string) *v2.Resource {
+	t.Helper()
+
+	teams := listAll(ctx, t, builder)
+	i := slices.IndexFunc(teams, func(team *v2.Resource) bool {
+		return team.Id.Resource == teamID
+	})
+	if i < 0 {
+		t.Fatalf("the team %s wasn't listed", teamID)
+	}
+
+	return teams[i]
+}
+
+// seedUser replaces the user with one belonging to the teams only, updated at the given time.
+func seedUser(t *testing.T, server *outreachtest.Server, userID int, updatedAt string, teamIDs ...int) {
+	t.Helper()
+
+	teams := make([]client.DataDetailPair, 0, len(teamIDs))
+	for _, teamID := range teamIDs {
+		teams = append(teams, client.DataDetailPair{Id: teamID, Type: "team"})
+	}
+
+	relationships, err := json.Marshal(teams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = server.Seed(client.ResourceObject{
+		Id:            userID,
+		Type:          "user",
+		Attributes:    []byte(fmt.Sprintf(`{"name":"User %d","updatedAt":%q}`, userID, updatedAt)),
+		Relationships: map[string]client.Relationship{"teams": {Data: relationships}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/conductorone/baton-outreach/pkg/connector/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
@@ -18,6 +19,11 @@ import (
 
 type userBuilder struct {
 	client *client.OutreachClient
+	// users keeps the relationships of every listed user, so Grants can be resolved without requesting each user again.
+	users *listedUsers
+	list  *deltaList[client.User]
+	// syncRoles tells whether the role resource type is synced, so the role of the users is requested and granted.
+	syncRoles bool
 }
 
 func (b *userBuilder) ResourceType(_ context.Context) *v2.ResourceType {
@@ -25,18 +31,14 @@ func (b *userBuilder) ResourceType(_ context.Context) *v2.ResourceType {
 }
 
 func (b *userBuilder) List(ctx context.Context, _ *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
-	var (
-		userResources []*v2.Resource
-		nextPageToken string
-	)
+	var userResources []*v2.Resource
 	outAnnotations := annotations.Annotations{}
 
-	bag, nextPage, err := client.GetToken(pToken.Token, &v2.ResourceId{ResourceType: userResourceType.Id})
-	if err != nil {
-		return nil, "", nil, err
+	if pToken.Token == "" {
+		b.users.reset()
 	}
 
-	users, nextPageLink, rateLimitData, err := b.client.ListAllUsers(ctx, nextPage)
+	users, nextPageToken, rateLimitData, err := b.list.page(ctx, pToken.Token)
 	if err != nil {
 		if rateLimitData != nil {
 			outAnnotations.WithRateLimiting(rateLimitData)
@@ -50,14 +52,12 @@ func (b *userBuilder) List(ctx context.Context, _ *v2.ResourceId, pToken *pagina
 			return nil, "", outAnnotations, err
 		}
 
+		b.users.add(userResource.Id.Resource, user.Relationships)
 		userResources = append(userResources, userResource)
 	}
 
-	if nextPageLink != "" {
-		nextPageToken, err = bag.NextToken(nextPageLink)
-		if err != nil {
-			return nil, "", outAnnotations, err
-		}
+	if nextPageToken == "" {
+		b.users.done(b.list.updatedSinceLastList())
 	}
 
 	return userResources, nextPageToken, outAnnotations, nil
@@ -68,27 +68,34 @@ func (b *userBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *paginat
 	return nil, "", nil, nil
 }
 
-// Grants implements the Grants function for profiles resource.
+// Grants implements the Grants function for the profile and role resources. Role grants are only emitted when the
+// roles are synced.
+// The relationships are taken from the data cached on List, and the user is only requested when it is missing from it.
 func (b *userBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
 	var grantResources []*v2.Grant
 	outAnnotations := annotations.Annotations{}
 
 	userID := resource.Id.Resource
 
-	user, rateLimitData, err := b.client.GetUserByID(ctx, userID)
-	if err != nil {
-		if rateLimitData != nil {
-			outAnnotations.WithRateLimiting(rateLimitData)
+	relationships, ok := b.users.get(userID)
+	if !ok {
+		user, rateLimitData, err := b.client.GetUserByID(ctx, userID)
+		if err != nil {
+			if rateLimitData != nil {
+				outAnnotations.WithRateLimiting(rateLimitData)
+			}
+
+			return nil, "", outAnnotations, err
 		}
 
-		return nil, "", outAnnotations, err
+		relationships = user.Relationships
 	}
 
-	if user.Relationships == nil || user.Relationships.Profile == nil || user.Relationships.Profile.Data == nil {
+	if relationships == nil || relationships.Profile == nil || relationships.Profile.Data == nil {
 		return nil, "", outAnnotations, status.Errorf(codes.NotFound, "user {%s} profile is missing", userID)
 	}
 
-	userProfile := user.Relationships.Profile
+	userProfile := relationships.Profile
 	profileResource := &v2.Resource{
 		Id: &v2.ResourceId{
 			ResourceType: profileResourceType.Id,
@@ -98,6 +105,18 @@ func (b *userBuilder) Grants(ctx context.Context, resource *v2.Resource, _ *pagi
 
 	grantResources = append(grantResources, grant.NewGrant(profileResource, profilePermissionName, resource))
 
+	// Unlike the profile, the role is optional.
+	if b.syncRoles && relationships.Role != nil && relationships.Role.Data != nil {
+		roleResource := &v2.Resource{
+			Id: &v2.ResourceId{
+				ResourceType: roleResourceType.Id,
+				Resource:     strconv.Itoa(relationships.Role.Data.Id),
+			},
+		}
+
+		grantResources = append(grantResources, grant.NewGrant(roleResource, rolePermissionName, resource))
+	}
+
 	return grantResources, "", outAnnotations, nil
 }
 
@@ -115,6 +134,7 @@ func (b *userBuilder) CreateAccount(
 	accountInfo *v2.AccountInfo,
 	_ *v2.CredentialOptions,
 ) (connectorbuilder.CreateAccountResponse, []*v2.PlaintextData, annotations.Annotations, error) {
+	ctx = client.ContextWithProvisioning(ctx)
 	outAnnotations := annotations.Annotations{}
 
 	newUserInfo, err := createNewUserInfo(accountInfo)
@@ -176,6 +196,7 @@ func createNewUserInfo(accountInfo *v2.AccountInfo) (*client.NewUserBody, error)
 }
 
 func (b *userBuilder) Delete(ctx context.Context, principal *v2.ResourceId) (annotations.Annotations, error) {
+	ctx = client.ContextWithProvisioning(ctx)
 	outAnnotations := annotations.Annotations{}
 
 	userID := principal.Resource
@@ -221,6 +242,15 @@ func parseIntoUserResource(user client.User) (*v2.Resource, error) {
 		"title":      user.Attributes.Title,
 	}
 
+	// The SDK has no manager fields on the user trait, so the manager is kept in the profile, where access reviews
+	// can be routed from.
+	if managerID := user.ManagerID(); managerID != 0 {
+		profile["manager_id"] = strconv.Itoa(managerID)
+		if user.Manager != nil {
+			profile["manager_email"] = user.Manager.Attributes.Email
+		}
+	}
+
 	if user.Attributes.Locked {
 		userStatus = v2.UserTrait_Status_STATUS_DISABLED
 	} else {
@@ -248,8 +278,17 @@ func parseIntoUserResource(user client.User) (*v2.Resource, error) {
 	return ret, nil
 }
 
-func newUserBuilder(c *client.OutreachClient) *userBuilder {
+func newUserBuilder(c *client.OutreachClient, users *listedUsers, fullSyncInterval time.Duration, syncRoles bool) *userBuilder {
+	listPage := func(ctx context.Context, cursor string, updatedAt client.UpdatedAtRange) ([]*client.User, string, *v2.RateLimitDescription, error) {
+		return c.ListAllUsers(ctx, cursor, updatedAt, syncRoles)
+	}
+
 	return &userBuilder{
 		client: c,
+		users:  users,
+		list: newDeltaList(userResourceType.Id, fullSyncInterval, listPage, func(user *client.User) int {
+			return user.Id
+		}),
+		syncRoles: syncRoles,
 	}
 }
@@ -0,0 +1,401 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+	"github.com/conductorone/baton-outreach/pkg/outreachtest"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	rs "github.com/conductorone/baton-sdk/pkg/types/resource"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestUserBuilderList(t *testing.T) {
+	tests := []struct {
+		name         string
+		pageSize     int
+		failStatus   int
+		wantIDs      []string
+		wantManagers map[string]string
+		wantCode     codes.Code
+	}{
+		{
+			name:         "single page",
+			pageSize:     100,
+			wantIDs:      []string{"1", "2", "3", "4"},
+			wantManagers: defaultManagers,
+		},
+		{
+			name:         "several pages",
+			pageSize:     3,
+			wantIDs:      []string{"1", "2", "3", "4"},
+			wantManagers: defaultManagers,
+		},
+		{
+			name:       "server error",
+			pageSize:   100,
+			failStatus: http.StatusInternalServerError,
+			wantCode:   codes.Unavailable,
+		},
+		{
+			name:       "rate limited",
+			pageSize:   100,
+			failStatus: http.StatusTooManyRequests,
+			wantCode:   codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t, client.WithPageSize(tt.pageSize))
+			if tt.failStatus != 0 {
+				server.Fail(http.MethodGet, "/users", tt.failStatus, 1)
+			}
+
+			builder := newUserBuilder(c, newListedUsers(), 0, true)
+
+			var (
+				users []*v2.Resource
+				token string
+			)
+			for {
+				page, nextToken, annos, err := builder.List(ctx, nil, &pagination.Token{Token: token})
+				checkCode(t, err, tt.wantCode)
+				if err != nil {
+					if !annos.Contains(&v2.RateLimitDescription{}) {
+						t.Error("expected the rate limit data to be annotated")
+					}
+					return
+				}
+
+				users = append(users, page...)
+				if nextToken == "" {
+					break
+				}
+				token = nextToken
+			}
+
+			if got := resourceIDs(users); !slices.Equal(got, tt.wantIDs) {
+				t.Errorf("expected the users %v, got %v", tt.wantIDs, got)
+			}
+
+			for _, user := range users {
+				trait, err := rs.GetUserTrait(user)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var got string
+				if managerID, ok := rs.GetProfileStringValue(trait.Profile, "manager_id"); ok {
+					managerEmail, _ := rs.GetProfileStringValue(trait.Profile, "manager_email")
+					got = managerID + " " + managerEmail
+				}
+				if want := tt.wantManagers[user.Id.Resource]; got != want {
+					t.Errorf("expected the user %s to have the manager %q, got %q", user.Id.Resource, want, got)
+				}
+			}
+
+			if slices.ContainsFunc(server.Requests(), func(r outreachtest.Request) bool {
+				return strings.HasPrefix(r.Path, "/users/")
+			}) {
+				t.Error("expected the managers to be resolved from the listed users, without requesting them")
+			}
+		})
+	}
+}
+
+// defaultManagers are the ID and email of the manager of each user of the default fixtures.
+var defaultManagers = map[string]string{
+	"1": "2 grace@outreachtest.example",
+	"3": "2 grace@outreachtest.example",
+	"4": "3 alan@outreachtest.example",
+}
+
+func TestUserBuilderGrants(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		listFirst   bool
+		wantProfile string
+		wantRole    string
+		wantCode    codes.Code
+		wantFetch   bool
+	}{
+		{
+			name:        "from the listed users",
+			userID:      "1",
+			listFirst:   true,
+			wantProfile: "1",
+			wantRole:    "4",
+		},
+		{
+			name:        "user missing from the listed users",
+			userID:      "3",
+			wantProfile: "3",
+			wantFetch:   true,
+		},
+		{
+			name:      "user without profile",
+			userID:    "5",
+			wantCode:  codes.NotFound,
+			wantFetch: true,
+		},
+		{
+			name:      "unknown user",
+			userID:    "42",
+			wantCode:  codes.NotFound,
+			wantFetch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t)
+			err := server.Seed(client.ResourceObject{
+				Id:         5,
+				Type:       "user",
+				Attributes: []byte(`{"name":"No Profile","email":"no-profile@outreachtest.example"}`),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			builder := newUserBuilder(c, newListedUsers(), 0, true)
+			if tt.listFirst {
+				_, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			grants, _, _, err := builder.Grants(ctx, resourceOf(userResourceType, tt.userID), &pagination.Token{})
+			checkCode(t, err, tt.wantCode)
+
+			fetched := slices.ContainsFunc(server.Requests(), func(r outreachtest.Request) bool {
+				return r.Method == http.MethodGet && r.Path == "/users/"+tt.userID
+			})
+			if fetched != tt.wantFetch {
+				t.Errorf("expected the user to be requested: %t, got %t", tt.wantFetch, fetched)
+			}
+
+			if err != nil {
+				return
+			}
+
+			want := []string{"profile:" + tt.wantProfile}
+			if tt.wantRole != "" {
+				want = append(want, "role:"+tt.wantRole)
+			}
+
+			var got []string
+			for _, grant := range grants {
+				got = append(got, grant.Entitlement.Resource.Id.ResourceType+":"+grant.Entitlement.Resource.Id.Resource)
+				if grant.Principal.Id.Resource != tt.userID {
+					t.Errorf("expected the grant to be given to the user %s, got %s", tt.userID, grant.Principal.Id.Resource)
+				}
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("expected the grants %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestUserBuilderListedAgain(t *testing.T) {
+	ctx := context.Background()
+	server, c := newTestServer(t)
+	users := newListedUsers()
+
+	if _, _, _, err := newUserBuilder(c, users, 0, true).List(ctx, nil, &pagination.Token{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The user moves to another profile before the next sync, which starts with a new client, without the responses
+	// cached by the previous one.
+	err := server.Seed(client.ResourceObject{
+		Id:            1,
+		Type:          "user",
+		Attributes:    []byte(`{"name":"Ada Lovelace","email":"ada@outreachtest.example"}`),
+		Relationships: map[string]client.Relationship{"profile": {Data: []byte(`{"id":2,"type":"profile"}`)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = server.NewClient(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := newUserBuilder(c, users, 0, true)
+	if _, _, _, err := builder.List(ctx, nil, &pagination.Token{}); err != nil {
+		t.Fatal(err)
+	}
+
+	grants, _, _, err := builder.Grants(ctx, resourceOf(userResourceType, "1"), &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(grants) != 1 || grants[0].Entitlement.Resource.Id.Resource != "2" {
+		t.Errorf("expected the user to be granted the profile 2 only, got %v", grants)
+	}
+}
+
+func TestUserBuilderWithoutRoleScope(t *testing.T) {
+	ctx := context.Background()
+	server, d := newTestConnector(t, []string{"users.all", "teams.all", "profiles.read", "mailboxes.read", "sequences.all"})
+
+	var builder connectorbuilder.ResourceSyncer
+	for _, syncer := range d.ResourceSyncers(ctx) {
+		if syncer.ResourceType(ctx).Id == userResourceType.Id {
+			builder = syncer
+		}
+	}
+	if builder == nil {
+		t.Fatal("expected the users to be synced")
+	}
+
+	_, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("expected the users to be listed without the roles.read scope: %v", err)
+	}
+
+	grants, _, _, err := builder.Grants(ctx, resourceOf(userResourceType, "1"), &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, grant := range grants {
+		if grant.Entitlement.Resource.Id.ResourceType != profileResourceType.Id {
+			t.Errorf("expected only the profile to be granted, got a grant of %s", grant.Entitlement.Resource.Id.ResourceType)
+		}
+	}
+	if len(grants) != 1 {
+		t.Errorf("expected the profile grant, got %d grants", len(grants))
+	}
+
+	for _, request := range server.Requests() {
+		if strings.Contains(request.Query.Get("include"), "role") {
+			t.Errorf("expected the role not to be included, got %s", request.Query.Get("include"))
+		}
+	}
+}
+
+func TestUserBuilderCreateAccount(t *testing.T) {
+	tests := []struct {
+		name     string
+		login    string
+		profile  map[string]interface{}
+		wantCode codes.Code
+		wantErr  bool
+	}{
+		{
+			name:    "new user",
+			login:   "barbara@outreachtest.example",
+			profile: map[string]interface{}{"first_name": "Barbara", "last_name": "Liskov"},
+		},
+		{
+			name:    "missing last name",
+			login:   "barbara@outreachtest.example",
+			profile: map[string]interface{}{"first_name": "Barbara"},
+			wantErr: true,
+		},
+		{
+			name:     "email already taken",
+			login:    "ada@outreachtest.example",
+			profile:  map[string]interface{}{"first_name": "Ada", "last_name": "Lovelace"},
+			wantCode: codes.InvalidArgument,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t)
+
+			profile, err := structpb.NewStruct(tt.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			response, _, _, err := newUserBuilder(c, newListedUsers(), 0, true).CreateAccount(ctx, &v2.AccountInfo{Login: tt.login, Profile: profile}, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected the account creation to fail")
+				}
+				if tt.wantCode != codes.OK {
+					checkCode(t, err, tt.wantCode)
+				}
+				return
+			}
+			checkCode(t, err, codes.OK)
+
+			result, ok := response.(*v2.CreateAccountResponse_SuccessResult)
+			if !ok {
+				t.Fatalf("unexpected response %T", response)
+			}
+
+			var user client.User
+			if !server.Resource("user", 5, &user) {
+				t.Fatal("expected the user to be created")
+			}
+			if result.Resource.Id.Resource != "5" || user.Attributes.Email != tt.login {
+				t.Errorf("unexpected user created: %v", result.Resource)
+			}
+		})
+	}
+}
+
+func TestUserBuilderDelete(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   string
+		wantCode codes.Code
+	}{
+		{
+			name:   "active user",
+			userID: "1",
+		},
+		{
+			name:   "locked user",
+			userID: "4",
+		},
+		{
+			name:     "unknown user",
+			userID:   "42",
+			wantCode: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			server, c := newTestServer(t)
+
+			_, err := newUserBuilder(c, newListedUsers(), 0, true).Delete(ctx, resourceOf(userResourceType, tt.userID).Id)
+			checkCode(t, err, tt.wantCode)
+			if err != nil {
+				return
+			}
+
+			userID, _ := strconv.Atoi(tt.userID)
+
+			var user client.User
+			if !server.Resource("user", userID, &user) || !user.Attributes.Locked {
+				t.Errorf("expected the user %s to be locked", tt.userID)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package outreachtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+)
+
+// serveCreateBatch applies the records of a batch right away, and stores the result of each of them as a batch item.
+// The batch is reported as processing until it is read once, so clients have to poll it like on Outreach.
+func (s *Server) serveCreateBatch(w http.ResponseWriter, attributes map[string]any) {
+	recordType, _ := attributes["recordType"].(string)
+	if attributes["action"] != client.BatchActionUpdate {
+		writeError(w, http.StatusUnprocessableEntity, "validationError", fmt.Sprintf("Unsupported batch action '%v'", attributes["action"]))
+		return
+	}
+	if _, ok := collections[collectionOf(recordType)]; !ok || recordType == "batch" || recordType == "batchItem" {
+		writeError(w, http.StatusUnprocessableEntity, "validationError", fmt.Sprintf("Unsupported batch record type '%s'", recordType))
+		return
+	}
+
+	var records []client.ResourceObject
+	rawRecords, _ := json.Marshal(attributes["records"])
+	if err := json.Unmarshal(rawRecords, &records); err != nil || len(records) == 0 {
+		writeError(w, http.StatusUnprocessableEntity, "validationError", "records can't be blank")
+		return
+	}
+
+	now := s.now().UTC().Format(time.RFC3339)
+	batch := &resource{
+		id:           s.store.nextID("batch"),
+		resourceType: "batch",
+		attributes: map[string]any{
+			"action":     client.BatchActionUpdate,
+			"recordType": recordType,
+			"state":      client.BatchStateProcessing,
+			"createdAt":  now,
+			"updatedAt":  now,
+		},
+		relationships: make(map[string][]int),
+	}
+	s.store.resources["batch"][batch.id] = batch
+
+	for _, record := range records {
+		itemAttributes := map[string]any{
+			"recordId":     record.Id,
+			"recordType":   recordType,
+			"state":        client.BatchItemStateSuccess,
+			"errorMessage": "",
+		}
+		if err := s.updateRecord(recordType, record); err != nil {
+			itemAttributes["state"] = client.BatchItemStateError
+			itemAttributes["errorMessage"] = err.Error()
+		}
+
+		item := &resource{
+			id:            s.store.nextID("batchItem"),
+			resourceType:  "batchItem",
+			attributes:    itemAttributes,
+			relationships: make(map[string][]int),
+		}
+		s.store.resources["batchItem"][item.id] = item
+		s.store.link(item, "batch", []int{batch.id})
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{"data": s.object(batch)})
+}
+
+// updateRecord applies a record of a batch, honoring the faults injected on the PATCH requests of the resource.
+func (s *Server) updateRecord(recordType string, record client.ResourceObject) error {
+	if record.Type != recordType {
+		return fmt.Errorf("the type of the record must be '%s'", recordType)
+	}
+
+	res, ok := s.store.get(recordType, record.Id)
+	if !ok {
+		return fmt.Errorf("could not find '%s' with ID '%d'", recordType, record.Id)
+	}
+
+	if f, ok := s.fault(http.MethodPatch, "/"+collectionOf(recordType)+"/"+strconv.Itoa(record.Id)); ok {
+		if f.dropped {
+			return nil
+		}
+		return errors.New(http.StatusText(f.statusCode))
+	}
+
+	attributes, err := decodeAttributes(&record)
+	if err != nil {
+		return err
+	}
+
+	return s.update(res, attributes, record.Relationships)
+}
+
+// completeBatch completes a processing batch, once it has been read.
+func completeBatch(batch *resource) {
+	if batch.attributes["state"] == client.BatchStateProcessing {
+		batch.attributes["state"] = client.BatchStateComplete
+	}
+}
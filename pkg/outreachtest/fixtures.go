@@ -0,0 +1,49 @@
+package outreachtest
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+)
+
+//go:embed fixtures/default.json
+var defaultFixtures []byte
+
+// Fixtures are the resource objects a server is seeded with. Relationships are given on either side,
+// like the users of a team, and the inverse side is filled in by the server.
+type Fixtures []client.ResourceObject
+
+// LoadFixtures reads fixtures from a JSON:API document holding the resource objects as its primary data.
+func LoadFixtures(r io.Reader) (Fixtures, error) {
+	var document struct {
+		Data Fixtures `json:"data"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("outreachtest: invalid fixtures: %w", err)
+	}
+
+	return document.Data, nil
+}
+
+// DefaultFixtures returns a small organization:
+//   - the profiles 'Admin' (1), 'Default' (2) and 'Sales Rep' (3);
+//   - the users 1 to 4, user 4 being locked. User 2 manages the users 1 and 3, and user 3 manages user 4;
+//   - the teams 'Engineering' (1) with the users 1 and 2, 'Sales' (2) with the users 2 and 4, and 'Empty' (3);
+//   - the roles 'CEO' (1), its children 'VP of Sales' (2) and 'CTO' (4), and 'Account Executive' (3) under
+//     'VP of Sales'. The users 1, 2 and 4 have the roles 4, 2 and 3, and user 3 has none;
+//   - the mailboxes of the users 1 (1) and 2 (2), whose sync is disabled, and the mailbox 3 without a user;
+//   - the sequences 'Enterprise outbound' (1) of user 2, shared with team 2, 'Follow-up' (2), private to user 1,
+//     and 'Onboarding' (3) of the locked user 4, shared read-only with the teams 1 and 2.
+func DefaultFixtures() Fixtures {
+	fixtures, err := LoadFixtures(bytes.NewReader(defaultFixtures))
+	if err != nil {
+		panic(err)
+	}
+
+	return fixtures
+}
@@ -0,0 +1,772 @@
+// Package outreachtest provides an in-process fake of the Outreach API, to test the connector and to develop it
+// without an Outreach organization.
+package outreachtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+)
+
+const (
+	// BasePath is the path of the API on the server, like on the Outreach API.
+	BasePath = "/api/v2"
+	// DefaultToken is the access token accepted by the server unless another one is set.
+	DefaultToken = "outreachtest-token"
+
+	contentType     = "application/vnd.api+json"
+	defaultPageSize = 50
+	maxPageSize     = 1000
+	rateLimit       = 10000
+)
+
+// DefaultScopes are the scopes of the token unless others are set, enough to sync and provision every resource.
+var DefaultScopes = []string{"users.all", "teams.all", "profiles.read", "roles.read", "mailboxes.read", "sequences.all"}
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+}
+
+type fault struct {
+	method     string
+	path       string
+	statusCode int
+	remaining  int
+	// dropped updates are answered as if they succeeded, without being applied.
+	dropped bool
+}
+
+// Server is a fake of the Outreach API serving the users, teams, profiles, roles and batches endpoints from fixtures.
+// Collections are paginated with cursors, and updates follow the Outreach semantics: PATCH merges the attributes and
+// replaces the relationships it sends, and the inverse relationships, like the teams of a user, are kept in sync.
+type Server struct {
+	// URL is the base URL of the API, to use as the base URL of the client.
+	URL string
+
+	server *httptest.Server
+
+	mu        sync.Mutex
+	token     string
+	scopes    []string
+	store     *store
+	requests  []Request
+	faults    []*fault
+	remaining int
+	// linkageLimit caps the resources embedded in the to-many relationships, like Outreach does.
+	linkageLimit int
+	now          func() time.Time
+}
+
+// NewServer starts a server seeded with the fixtures. It must be closed when done.
+func NewServer(fixtures Fixtures) (*Server, error) {
+	s := &Server{
+		token:     DefaultToken,
+		scopes:    DefaultScopes,
+		store:     newStore(),
+		remaining: rateLimit,
+		now:       time.Now,
+	}
+
+	if err := s.store.seed(fixtures); err != nil {
+		return nil, err
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL + BasePath
+
+	return s, nil
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// NewClient returns a client of the server, authenticated with its token. Options are applied after the server ones.
+func (s *Server) NewClient(ctx context.Context, opts ...client.ConfigOption) (*client.OutreachClient, error) {
+	s.mu.Lock()
+	token := s.token
+	s.mu.Unlock()
+
+	return client.New(ctx, append([]client.ConfigOption{client.WithBaseURL(s.URL), client.WithAccessToken(token)}, opts...)...)
+}
+
+// SetScopes sets the scopes of the token, as returned by the root of the API.
+func (s *Server) SetScopes(scopes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scopes = scopes
+}
+
+// SetRateLimitRemaining sets how many requests are left in the hourly budget, as reported by the next responses.
+func (s *Server) SetRateLimitRemaining(remaining int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remaining = remaining
+}
+
+// SetLinkageLimit sets how many resources are embedded at most in the to-many relationships of the responses,
+// like the users of a team. The related link of the relationship lists all of them. Zero embeds them all.
+func (s *Server) SetLinkageLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.linkageLimit = limit
+}
+
+// Seed adds the objects to the server, replacing the ones with the same type and ID.
+func (s *Server) Seed(objects ...client.ResourceObject) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.store.seed(objects)
+}
+
+// Fail makes the next requests matching the method and the path, relative to the base URL like "/users/1", fail
+// with the status code. An empty method or path matches any. 429 responses ask to retry after a second.
+// Failed PATCH requests also fail the updates of the resource within batches, as errored batch items.
+func (s *Server) Fail(method, path string, statusCode, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &fault{
+		method:     method,
+		path:       path,
+		statusCode: statusCode,
+		remaining:  times,
+	})
+}
+
+// DropUpdates makes the next updates of the resource at the path, like "/teams/1", succeed without being applied,
+// as if a concurrent update overwrote them right away. Updates of the resource within batches are dropped too.
+func (s *Server) DropUpdates(path string, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &fault{
+		method:    http.MethodPatch,
+		path:      path,
+		remaining: times,
+		dropped:   true,
+	})
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.requests)
+}
+
+// Resource decodes the current state of a resource into v, like a *client.User, with all its relationships.
+// It reports whether the resource exists.
+func (s *Server) Resource(resourceType string, id int, v any) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.store.get(resourceType, id)
+	if !ok {
+		return false
+	}
+
+	return s.store.object(r, s.URL, 0).Decode(v) == nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, BasePath), "/")
+	s.requests = append(s.requests, Request{Method: r.Method, Path: path, Query: r.URL.Query()})
+
+	s.remaining = max(s.remaining-1, 0)
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(rateLimit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(s.remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(s.now().Truncate(time.Hour).Add(time.Hour).Unix(), 10))
+
+	if !strings.HasPrefix(r.URL.Path, BasePath) {
+		writeError(w, http.StatusNotFound, "notFound", "Unknown endpoint")
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer "+s.token {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "The access token is invalid")
+		return
+	}
+
+	if f, ok := s.fault(r.Method, path); ok {
+		if f.dropped {
+			s.serveDropped(w, path)
+			return
+		}
+
+		if f.statusCode == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		writeError(w, f.statusCode, "injectedFailure", http.StatusText(f.statusCode))
+		return
+	}
+
+	if path == "" {
+		s.serveTokenInfo(w, r)
+		return
+	}
+
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	resourceType, ok := collections[segments[0]]
+	if !ok || len(segments) > 2 {
+		writeError(w, http.StatusNotFound, "notFound", "Unknown endpoint")
+		return
+	}
+
+	if len(segments) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			s.serveList(w, r, resourceType)
+		case http.MethodPost:
+			s.serveCreate(w, r, resourceType)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "methodNotAllowed", "Method not allowed")
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(segments[1])
+	if err != nil {
+		writeError(w, http.StatusNotFound, "notFound", "Unknown endpoint")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.serveGet(w, r, resourceType, id)
+	case http.MethodPatch:
+		s.servePatch(w, r, resourceType, id)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "methodNotAllowed", "Method not allowed")
+	}
+}
+
+func (s *Server) fault(method, path string) (fault, bool) {
+	for i, f := range s.faults {
+		if (f.method != "" && f.method != method) || (f.path != "" && f.path != path) {
+			continue
+		}
+
+		f.remaining--
+		if f.remaining <= 0 {
+			s.faults = slices.Delete(s.faults, i, i+1)
+		}
+
+		return *f, true
+	}
+
+	return fault{}, false
+}
+
+// serveDropped answers an update with the resource left as it is.
+func (s *Server) serveDropped(w http.ResponseWriter, path string) {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(segments) != 2 {
+		writeError(w, http.StatusNotFound, "notFound", "Unknown endpoint")
+		return
+	}
+
+	id, _ := strconv.Atoi(segments[1])
+	res, ok := s.store.get(collections[segments[0]], id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "resourceNotFound", fmt.Sprintf("Could not find '%s' with ID '%d'.", collections[segments[0]], id))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": s.object(res)})
+}
+
+func (s *Server) serveTokenInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "methodNotAllowed", "Method not allowed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"meta": map[string]any{
+			"token": map[string]any{
+				"scopes": s.scopes,
+			},
+			"user": map[string]any{
+				"email":   "admin@outreachtest.example",
+				"orgName": "Outreach Test",
+				"orgGuid": "00000000-0000-0000-0000-000000000001",
+				"orgId":   1,
+			},
+		},
+	})
+}
+
+func (s *Server) serveList(w http.ResponseWriter, r *http.Request, resourceType string) {
+	query := r.URL.Query()
+
+	pageSize := defaultPageSize
+	if rawSize := query.Get("page[size]"); rawSize != "" {
+		size, err := strconv.Atoi(rawSize)
+		if err != nil || size <= 0 || size > maxPageSize {
+			writeError(w, http.StatusBadRequest, "invalidPageSize", fmt.Sprintf("The page size must be between 1 and %d", maxPageSize))
+			return
+		}
+		pageSize = size
+	}
+
+	if sortBy := query.Get("sort"); sortBy != "" && sortBy != "id" {
+		writeError(w, http.StatusBadRequest, "invalidSort", "Only sorting by id is supported")
+		return
+	}
+
+	after := 0
+	if cursor := query.Get("page[after]"); cursor != "" {
+		var err error
+		after, err = decodeCursor(cursor)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalidCursor", "The page cursor is invalid")
+			return
+		}
+	}
+
+	includes, ok := parseInclude(query.Get("include"), resourceType)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalidInclude", "Unknown relationship to include")
+		return
+	}
+
+	if !s.canRead(resourceType, includes) {
+		writeError(w, http.StatusForbidden, "forbidden", "The token lacks the scope to read the requested resources")
+		return
+	}
+
+	matches, err := s.filter(resourceType, query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+
+	var page []*resource
+	hasNext := false
+	for _, res := range matches {
+		if res.id <= after {
+			continue
+		}
+		if len(page) == pageSize {
+			hasNext = true
+			break
+		}
+		page = append(page, res)
+	}
+
+	data := make([]client.ResourceObject, 0, len(page))
+	for _, res := range page {
+		data = append(data, s.object(res))
+	}
+
+	links := map[string]string{}
+	firstQuery := cloneQuery(query)
+	firstQuery.Del("page[after]")
+	links["first"] = s.URL + "/" + collectionOf(resourceType) + "?" + firstQuery.Encode()
+	if hasNext {
+		nextQuery := cloneQuery(query)
+		nextQuery.Set("page[after]", encodeCursor(page[len(page)-1].id))
+		links["next"] = s.URL + "/" + collectionOf(resourceType) + "?" + nextQuery.Encode()
+	}
+
+	document := map[string]any{
+		"data":  data,
+		"links": links,
+		"meta":  map[string]any{"count": len(matches)},
+	}
+	if len(includes) > 0 {
+		document["included"] = s.objects(s.store.included(page, includes))
+	}
+
+	writeJSON(w, http.StatusOK, document)
+}
+
+// filter returns the resources matching the filters of the query: 'filter[updatedAt]=from..to' ranges,
+// 'filter[<relationship>][id]=1,2' on relationships and 'filter[<attribute>]=a,b' on attributes.
+func (s *Server) filter(resourceType string, query url.Values) ([]*resource, error) {
+	var matches []*resource
+	for _, res := range s.store.sorted(resourceType) {
+		matched := true
+		for key, values := range query {
+			if !strings.HasPrefix(key, "filter[") {
+				continue
+			}
+
+			ok, err := matchFilter(res, strings.TrimPrefix(key, "filter"), values[0])
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			matches = append(matches, res)
+		}
+	}
+
+	return matches, nil
+}
+
+func matchFilter(res *resource, field, value string) (bool, error) {
+	names := strings.Split(strings.Trim(field, "[]"), "][")
+	accepted := strings.Split(value, ",")
+
+	switch {
+	case len(names) == 1 && names[0] == "id":
+		return slices.Contains(accepted, strconv.Itoa(res.id)), nil
+
+	case len(names) == 1 && names[0] == "updatedAt":
+		return matchUpdatedAt(res, value)
+
+	case len(names) == 1:
+		attribute, ok := res.attributes[names[0]]
+		if !ok {
+			return false, fmt.Errorf("unknown filter %s", field)
+		}
+		return slices.Contains(accepted, fmt.Sprint(attribute)), nil
+
+	case len(names) == 2 && names[1] == "id":
+		if _, ok := schema[res.resourceType][names[0]]; !ok {
+			return false, fmt.Errorf("unknown filter %s", field)
+		}
+		for _, id := range res.relationships[names[0]] {
+			if slices.Contains(accepted, strconv.Itoa(id)) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	return false, fmt.Errorf("unknown filter %s", field)
+}
+
+func matchUpdatedAt(res *resource, value string) (bool, error) {
+	from, to, ok := strings.Cut(value, "..")
+	if !ok {
+		return false, fmt.Errorf("invalid updatedAt range %s", value)
+	}
+
+	updatedAt, err := time.Parse(time.RFC3339, fmt.Sprint(res.attributes["updatedAt"]))
+	if err != nil {
+		return false, nil
+	}
+
+	if from != "neginf" {
+		fromTime, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return false, fmt.Errorf("invalid updatedAt range %s", value)
+		}
+		if updatedAt.Before(fromTime) {
+			return false, nil
+		}
+	}
+
+	if to != "inf" {
+		toTime, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return false, fmt.Errorf("invalid updatedAt range %s", value)
+		}
+		if updatedAt.After(toTime) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (s *Server) serveGet(w http.ResponseWriter, r *http.Request, resourceType string, id int) {
+	res, ok := s.store.get(resourceType, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "resourceNotFound", fmt.Sprintf("Could not find '%s' with ID '%d'.", resourceType, id))
+		return
+	}
+
+	includes, ok := parseInclude(r.URL.Query().Get("include"), resourceType)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalidInclude", "Unknown relationship to include")
+		return
+	}
+
+	if !s.canRead(resourceType, includes) {
+		writeError(w, http.StatusForbidden, "forbidden", "The token lacks the scope to read the requested resources")
+		return
+	}
+
+	document := map[string]any{
+		"data": s.object(res),
+	}
+	if len(includes) > 0 {
+		document["included"] = s.objects(s.store.included([]*resource{res}, includes))
+	}
+
+	if resourceType == "batch" {
+		completeBatch(res)
+	}
+
+	writeJSON(w, http.StatusOK, document)
+}
+
+func (s *Server) servePatch(w http.ResponseWriter, r *http.Request, resourceType string, id int) {
+	res, ok := s.store.get(resourceType, id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "resourceNotFound", fmt.Sprintf("Could not find '%s' with ID '%d'.", resourceType, id))
+		return
+	}
+
+	object, ok := decodeDocument(w, r, resourceType)
+	if !ok {
+		return
+	}
+
+	if object.Id != id {
+		writeError(w, http.StatusUnprocessableEntity, "validationError", "The ID of the resource doesn't match the URL")
+		return
+	}
+
+	attributes, err := decodeAttributes(object)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalidAttributes", err.Error())
+		return
+	}
+
+	if err := s.update(res, attributes, object.Relationships); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "validationError", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": s.object(res)})
+}
+
+// update merges the attributes into the resource and replaces the given relationships, like a PATCH does.
+func (s *Server) update(res *resource, attributes map[string]any, relationships map[string]client.Relationship) error {
+	if err := s.store.setRelationships(res, relationships); err != nil {
+		return err
+	}
+
+	for name, value := range attributes {
+		res.attributes[name] = value
+	}
+	res.attributes["updatedAt"] = s.now().UTC().Format(time.RFC3339)
+
+	return nil
+}
+
+func (s *Server) serveCreate(w http.ResponseWriter, r *http.Request, resourceType string) {
+	object, ok := decodeDocument(w, r, resourceType)
+	if !ok {
+		return
+	}
+
+	attributes, err := decodeAttributes(object)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalidAttributes", err.Error())
+		return
+	}
+
+	if resourceType == "batch" {
+		s.serveCreateBatch(w, attributes)
+		return
+	}
+
+	if resourceType == "user" {
+		if msg, ok := s.validateNewUser(attributes); !ok {
+			writeError(w, http.StatusUnprocessableEntity, "validationError", msg)
+			return
+		}
+	}
+
+	now := s.now().UTC().Format(time.RFC3339)
+	attributes["createdAt"] = now
+	attributes["updatedAt"] = now
+
+	res := &resource{
+		id:            s.store.nextID(resourceType),
+		resourceType:  resourceType,
+		attributes:    attributes,
+		relationships: make(map[string][]int),
+	}
+	s.store.resources[resourceType][res.id] = res
+
+	if resourceType == "user" {
+		attributes["name"] = fmt.Sprintf("%v %v", attributes["firstName"], attributes["lastName"])
+		attributes["locked"] = false
+		if profileID, ok := s.defaultProfileID(); ok {
+			s.store.link(res, "profile", []int{profileID})
+		}
+	}
+
+	if err := s.store.setRelationships(res, object.Relationships); err != nil {
+		delete(s.store.resources[resourceType], res.id)
+		writeError(w, http.StatusUnprocessableEntity, "validationError", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{"data": s.object(res)})
+}
+
+func (s *Server) validateNewUser(attributes map[string]any) (string, bool) {
+	for _, name := range []string{"email", "firstName", "lastName"} {
+		if value, _ := attributes[name].(string); value == "" {
+			return fmt.Sprintf("%s can't be blank", name), false
+		}
+	}
+
+	for _, user := range s.store.resources["user"] {
+		if strings.EqualFold(fmt.Sprint(user.attributes["email"]), fmt.Sprint(attributes["email"])) {
+			return "email has already been taken", false
+		}
+	}
+
+	return "", true
+}
+
+// defaultProfileID returns the profile new users are given, the one with the 'default' special ID.
+func (s *Server) defaultProfileID() (int, bool) {
+	for _, profile := range s.store.sorted("profile") {
+		if profile.attributes["specialId"] == "default" {
+			return profile.id, true
+		}
+	}
+
+	return 0, false
+}
+
+func (s *Server) object(res *resource) client.ResourceObject {
+	return s.store.object(res, s.URL, s.linkageLimit)
+}
+
+func (s *Server) objects(resources []*resource) []client.ResourceObject {
+	objects := make([]client.ResourceObject, 0, len(resources))
+	for _, res := range resources {
+		objects = append(objects, s.object(res))
+	}
+
+	return objects
+}
+
+func decodeDocument(w http.ResponseWriter, r *http.Request, resourceType string) (*client.ResourceObject, bool) {
+	var document struct {
+		Data *client.ResourceObject `json:"data"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&document); err != nil || document.Data == nil {
+		writeError(w, http.StatusBadRequest, "invalidDocument", "The request body must be a JSON:API document")
+		return nil, false
+	}
+
+	if document.Data.Type != resourceType {
+		writeError(w, http.StatusUnprocessableEntity, "validationError", fmt.Sprintf("The type of the resource must be '%s'", resourceType))
+		return nil, false
+	}
+
+	return document.Data, true
+}
+
+func decodeAttributes(object *client.ResourceObject) (map[string]any, error) {
+	attributes := make(map[string]any)
+	if len(object.Attributes) == 0 {
+		return attributes, nil
+	}
+
+	if err := json.Unmarshal(object.Attributes, &attributes); err != nil {
+		return nil, err
+	}
+
+	return attributes, nil
+}
+
+// canRead reports whether the scopes of the token allow reading the resources of the type, and the ones included
+// through its relationships. Batches are read with the scope of the resources they update, so they are always readable.
+func (s *Server) canRead(resourceType string, includes []string) bool {
+	resourceTypes := []string{resourceType}
+	for _, name := range includes {
+		resourceTypes = append(resourceTypes, schema[resourceType][name].resourceType)
+	}
+
+	for _, resourceType := range resourceTypes {
+		if resourceType == "batch" || resourceType == "batchItem" {
+			continue
+		}
+
+		collection := collectionOf(resourceType)
+		if !slices.Contains(s.scopes, collection+".read") && !slices.Contains(s.scopes, collection+".all") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseInclude(include, resourceType string) ([]string, bool) {
+	if include == "" {
+		return nil, true
+	}
+
+	names := strings.Split(include, ",")
+	for _, name := range names {
+		if _, ok := schema[resourceType][name]; !ok {
+			return nil, false
+		}
+	}
+
+	return names, true
+}
+
+func encodeCursor(id int) string {
+	return "after-" + strconv.Itoa(id)
+}
+
+func decodeCursor(cursor string) (int, error) {
+	return strconv.Atoi(strings.TrimPrefix(cursor, "after-"))
+}
+
+func cloneQuery(query url.Values) url.Values {
+	clone := url.Values{}
+	for key, values := range query {
+		clone[key] = slices.Clone(values)
+	}
+
+	return clone
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, id, detail string) {
+	writeJSON(w, statusCode, map[string]any{
+		"errors": []client.ErrorObject{{
+			Id:     id,
+			Title:  http.StatusText(statusCode),
+			Detail: detail,
+		}},
+	})
+}
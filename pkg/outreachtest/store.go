@@ -0,0 +1,307 @@
+package outreachtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/conductorone/baton-outreach/pkg/connector/client"
+)
+
+// relationshipSchema describes a relationship of a resource type.
+type relationshipSchema struct {
+	resourceType string
+	toMany       bool
+	// inverse is the relationship of the related resources holding this one back, kept in sync on updates.
+	inverse string
+}
+
+// collections maps the endpoints served to the type of their resources.
+var collections = map[string]string{
+	"users":      "user",
+	"teams":      "team",
+	"profiles":   "profile",
+	"roles":      "role",
+	"mailboxes":  "mailbox",
+	"sequences":  "sequence",
+	"batches":    "batch",
+	"batchItems": "batchItem",
+}
+
+var schema = map[string]map[string]relationshipSchema{
+	"user": {
+		"profile": {resourceType: "profile"},
+		"role":    {resourceType: "role"},
+		"manager": {resourceType: "user"},
+		"teams":   {resourceType: "team", toMany: true, inverse: "users"},
+	},
+	"team": {
+		"users": {resourceType: "user", toMany: true, inverse: "teams"},
+	},
+	"profile": {},
+	"role": {
+		"parentRole": {resourceType: "role"},
+	},
+	"mailbox": {
+		"user": {resourceType: "user"},
+	},
+	"sequence": {
+		"owner": {resourceType: "user"},
+		"teams": {resourceType: "team", toMany: true},
+	},
+	"batch": {
+		"batchItems": {resourceType: "batchItem", toMany: true, inverse: "batch"},
+	},
+	"batchItem": {
+		"batch": {resourceType: "batch", inverse: "batchItems"},
+	},
+}
+
+// resource is a stored resource. To-one relationships hold at most one ID.
+type resource struct {
+	id            int
+	resourceType  string
+	attributes    map[string]any
+	relationships map[string][]int
+}
+
+// store holds the resources of the fake API. It is not safe for concurrent use, the server locks around it.
+type store struct {
+	resources map[string]map[int]*resource
+}
+
+func newStore() *store {
+	s := &store{
+		resources: make(map[string]map[int]*resource),
+	}
+	for _, resourceType := range collections {
+		s.resources[resourceType] = make(map[int]*resource)
+	}
+
+	return s
+}
+
+func (s *store) get(resourceType string, id int) (*resource, bool) {
+	r, ok := s.resources[resourceType][id]
+	return r, ok
+}
+
+// sorted returns the resources of the type sorted by ID.
+func (s *store) sorted(resourceType string) []*resource {
+	resources := make([]*resource, 0, len(s.resources[resourceType]))
+	for _, r := range s.resources[resourceType] {
+		resources = append(resources, r)
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].id < resources[j].id })
+
+	return resources
+}
+
+func (s *store) nextID(resourceType string) int {
+	nextID := 1
+	for id := range s.resources[resourceType] {
+		nextID = max(nextID, id+1)
+	}
+
+	return nextID
+}
+
+// seed adds the objects to the store, replacing the ones with the same type and ID.
+// Relationships are set once every object is stored, so objects can reference each other in any order.
+func (s *store) seed(objects []client.ResourceObject) error {
+	for _, object := range objects {
+		if _, ok := schema[object.Type]; !ok {
+			return fmt.Errorf("outreachtest: unsupported resource type %q", object.Type)
+		}
+
+		attributes := make(map[string]any)
+		if len(object.Attributes) > 0 {
+			if err := json.Unmarshal(object.Attributes, &attributes); err != nil {
+				return fmt.Errorf("outreachtest: invalid attributes for %s %d: %w", object.Type, object.Id, err)
+			}
+		}
+
+		s.resources[object.Type][object.Id] = &resource{
+			id:            object.Id,
+			resourceType:  object.Type,
+			attributes:    attributes,
+			relationships: make(map[string][]int),
+		}
+	}
+
+	for _, object := range objects {
+		r := s.resources[object.Type][object.Id]
+		if err := s.setRelationships(r, object.Relationships); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setRelationships replaces the given relationships of the resource, updating the inverse relationships.
+func (s *store) setRelationships(r *resource, relationships map[string]client.Relationship) error {
+	for name, relationship := range relationships {
+		relSchema, ok := schema[r.resourceType][name]
+		if !ok {
+			return fmt.Errorf("unknown relationship %q of %s", name, r.resourceType)
+		}
+
+		var identifiers []client.DataDetailPair
+		if relSchema.toMany {
+			many, err := relationship.Many()
+			if err != nil {
+				return fmt.Errorf("invalid relationship %q: %w", name, err)
+			}
+			identifiers = many
+		} else {
+			one, err := relationship.One()
+			if err != nil {
+				return fmt.Errorf("invalid relationship %q: %w", name, err)
+			}
+			if one != nil {
+				identifiers = append(identifiers, *one)
+			}
+		}
+
+		ids := make([]int, 0, len(identifiers))
+		for _, identifier := range identifiers {
+			if identifier.Type != relSchema.resourceType {
+				return fmt.Errorf("relationship %q expects %s resources, got %q", name, relSchema.resourceType, identifier.Type)
+			}
+			if _, ok := s.get(identifier.Type, identifier.Id); !ok {
+				return fmt.Errorf("relationship %q references the missing %s %d", name, identifier.Type, identifier.Id)
+			}
+			if !slices.Contains(ids, identifier.Id) {
+				ids = append(ids, identifier.Id)
+			}
+		}
+		slices.Sort(ids)
+
+		s.link(r, name, ids)
+	}
+
+	return nil
+}
+
+func (s *store) link(r *resource, name string, ids []int) {
+	relSchema := schema[r.resourceType][name]
+	previous := r.relationships[name]
+	r.relationships[name] = ids
+
+	if relSchema.inverse == "" {
+		return
+	}
+
+	for _, id := range previous {
+		if related, ok := s.get(relSchema.resourceType, id); ok && !slices.Contains(ids, id) {
+			related.relationships[relSchema.inverse] = slices.DeleteFunc(slices.Clone(related.relationships[relSchema.inverse]), func(inverseID int) bool {
+				return inverseID == r.id
+			})
+		}
+	}
+
+	for _, id := range ids {
+		related, ok := s.get(relSchema.resourceType, id)
+		if !ok || slices.Contains(related.relationships[relSchema.inverse], r.id) {
+			continue
+		}
+
+		inverseIDs := append(slices.Clone(related.relationships[relSchema.inverse]), r.id)
+		slices.Sort(inverseIDs)
+		related.relationships[relSchema.inverse] = inverseIDs
+	}
+}
+
+// object renders the resource as a JSON:API resource object. Like Outreach, at most linkageLimit resources are
+// embedded in the to-many relationships, when the limit is positive, and their related link lists all of them.
+func (s *store) object(r *resource, baseURL string, linkageLimit int) client.ResourceObject {
+	attributes, _ := json.Marshal(r.attributes)
+
+	relationships := make(map[string]client.Relationship)
+	for name, relSchema := range schema[r.resourceType] {
+		ids := r.relationships[name]
+
+		var (
+			data  any
+			links *client.RelationshipLinks
+			meta  *client.Meta
+		)
+		if relSchema.toMany {
+			if linkageLimit > 0 && len(ids) > linkageLimit {
+				ids = ids[:linkageLimit]
+			}
+
+			identifiers := make([]client.DataDetailPair, 0, len(ids))
+			for _, id := range ids {
+				identifiers = append(identifiers, client.DataDetailPair{Id: id, Type: relSchema.resourceType})
+			}
+			data = identifiers
+
+			if relSchema.inverse != "" {
+				filter := url.Values{}
+				filter.Set(fmt.Sprintf("filter[%s][id]", relSchema.inverse), strconv.Itoa(r.id))
+				links = &client.RelationshipLinks{
+					Related: baseURL + "/" + collectionOf(relSchema.resourceType) + "?" + filter.Encode(),
+				}
+			}
+			meta = &client.Meta{Count: len(r.relationships[name])}
+		} else if len(ids) > 0 {
+			data = client.DataDetailPair{Id: ids[0], Type: relSchema.resourceType}
+		}
+
+		rawData, _ := json.Marshal(data)
+		relationships[name] = client.Relationship{Data: rawData, Links: links, Meta: meta}
+	}
+
+	return client.ResourceObject{
+		Id:            r.id,
+		Type:          r.resourceType,
+		Attributes:    attributes,
+		Relationships: relationships,
+		Links: &client.ResourceLinks{
+			Self: baseURL + "/" + collectionOf(r.resourceType) + "/" + strconv.Itoa(r.id),
+		},
+	}
+}
+
+// included returns the resources related to the given ones through the relationships, without duplicates.
+// Like JSON:API requires, the given resources are never included again.
+func (s *store) included(resources []*resource, relationshipNames []string) []*resource {
+	seen := make(map[string]bool)
+	for _, r := range resources {
+		seen[r.resourceType+"/"+strconv.Itoa(r.id)] = true
+	}
+
+	var included []*resource
+	for _, r := range resources {
+		for _, name := range relationshipNames {
+			relSchema := schema[r.resourceType][name]
+			for _, id := range r.relationships[name] {
+				key := relSchema.resourceType + "/" + strconv.Itoa(id)
+				related, ok := s.get(relSchema.resourceType, id)
+				if !ok || seen[key] {
+					continue
+				}
+
+				seen[key] = true
+				included = append(included, related)
+			}
+		}
+	}
+
+	return included
+}
+
+func collectionOf(resourceType string) string {
+	for collection, collectionType := range collections {
+		if collectionType == resourceType {
+			return collection
+		}
+	}
+
+	return resourceType + "s"
+}